@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/htekgulds/terminal-rehber/pkg/tui"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/viper"
+)
+
+const (
+	peopleFile      = "people.json"
+	departmentsFile = "departments.json"
+	databaseFile    = "rehber.db"
+)
+
+// dataDirCandidates returns the directories searched for data files, in order
+func dataDirCandidates() []string {
+	if dir := viper.GetString("data.dir"); dir != "" {
+		return []string{dir}
+	}
+
+	candidates := []string{"data"}
+	if dir := userDataDir(); dir != "" {
+		candidates = append(candidates, dir)
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			candidates = append(candidates, filepath.Join(dir, cmdName))
+		}
+	}
+
+	return candidates
+}
+
+// userDataDir returns the per-user data directory, $XDG_DATA_HOME/rehber, or "" when there is no home directory
+func userDataDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, cmdName)
+}
+
+// resolveDataPaths finds the people and departments files using the data.* settings
+func resolveDataPaths() (string, string, error) {
+	peoplePath := viper.GetString("data.people")
+	departmentsPath := viper.GetString("data.departments")
+
+	var tried []string
+	check := func(path string) bool {
+		if _, err := os.Stat(path); err != nil {
+			tried = append(tried, path)
+			return false
+		}
+		return true
+	}
+
+	// Explicit file settings are used as-is and never fall back to other locations
+	if peoplePath != "" && departmentsPath != "" {
+		if check(peoplePath) && check(departmentsPath) {
+			return peoplePath, departmentsPath, nil
+		}
+		return "", "", notFoundError(tried)
+	}
+
+	for _, dir := range dataDirCandidates() {
+		people := peoplePath
+		if people == "" {
+			people = filepath.Join(dir, peopleFile)
+		}
+		departments := departmentsPath
+		if departments == "" {
+			departments = filepath.Join(dir, departmentsFile)
+		}
+
+		// Check both files so every missing path ends up in the error
+		peopleOk := check(people)
+		departmentsOk := check(departments)
+		if peopleOk && departmentsOk {
+			return people, departments, nil
+		}
+	}
+
+	return "", "", notFoundError(tried)
+}
+
+func notFoundError(tried []string) error {
+	var b strings.Builder
+	b.WriteString("data files not found, tried:")
+	seen := make(map[string]bool)
+	for _, path := range tried {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		b.WriteString("\n  - " + path)
+	}
+	b.WriteString("\nset data.dir in the config file, pass --data-dir or set REHBER_DATA_DIR")
+	return errors.New(b.String())
+}
+
+// databasePath returns the SQLite database location: data.database when set,
+// else the first data directory candidate holding a database, else the
+// directory of the JSON data files, else the per-user data directory
+func databasePath() string {
+	if path := viper.GetString("data.database"); path != "" {
+		return path
+	}
+
+	candidates := dataDirCandidates()
+	for _, dir := range candidates {
+		path := filepath.Join(dir, databaseFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	// A new database goes next to the JSON files it is usually imported from
+	if peoplePath, _, err := resolveDataPaths(); err == nil {
+		return filepath.Join(filepath.Dir(peoplePath), databaseFile)
+	}
+	if viper.GetString("data.dir") == "" {
+		if dir := userDataDir(); dir != "" {
+			return filepath.Join(dir, databaseFile)
+		}
+	}
+	return filepath.Join(candidates[0], databaseFile)
+}
+
+// openJSONDirectory resolves the JSON data files into a directory
+func openJSONDirectory() (*services.JSONDirectory, error) {
+	peoplePath, departmentsPath, err := resolveDataPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	return &services.JSONDirectory{
+		PeoplePath:      peoplePath,
+		DepartmentsPath: departmentsPath,
+	}, nil
+}
+
+// openDirectory opens the data source selected by data.driver; close must be called when done
+func openDirectory() (dir services.Directory, close func() error, err error) {
+	switch driver := viper.GetString("data.driver"); driver {
+	case "", "json":
+		dir, err := openJSONDirectory()
+		if err != nil {
+			return nil, nil, err
+		}
+		return dir, func() error { return nil }, nil
+
+	case "sqlite":
+		path := databasePath()
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("database %s not found, create it with `%s db init`", path, cmdName)
+		}
+
+		db, err := services.OpenSQLite(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown data.driver %q, expected json or sqlite", driver)
+	}
+}
+
+// openEditor opens the configured data source for writing; close must be called when done
+func openEditor() (services.Editor, func() error, error) {
+	dir, close, err := openDirectory()
+	if err != nil {
+		return nil, nil, err
+	}
+	editor, ok := dir.(services.Editor)
+	if !ok {
+		close()
+		return nil, nil, fmt.Errorf("data source cannot be written to")
+	}
+	return editor, close, nil
+}
+
+// loadStore resolves the configured data source and loads it into memory
+func loadStore() (*services.Store, error) {
+	dir, close, err := openDirectory()
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	store, err := services.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data: %w", err)
+	}
+	return store, nil
+}
+
+// loadAvailableStore is like loadStore but keeps whatever part of the data could be read
+func loadAvailableStore() (*services.Store, []error, error) {
+	dir, close, err := openDirectory()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer close()
+
+	store, loadErrors := services.LoadAvailable(dir)
+	return store, loadErrors, nil
+}
+
+// watchData reloads the data source whenever its files change and sends the result to program
+func watchData(program *tea.Program) (*services.Watcher, error) {
+	dir, close, err := openDirectory()
+	if err != nil {
+		return nil, err
+	}
+	close()
+
+	source, ok := dir.(services.Watchable)
+	if !ok {
+		return nil, fmt.Errorf("data source cannot be watched")
+	}
+
+	return services.Watch(source.SourcePaths(), func() {
+		store, loadErrors, err := loadAvailableStore()
+		if err != nil {
+			program.Send(tui.DataReloadedMsg{LoadErrors: []error{err}})
+			return
+		}
+		slog.Info("data reloaded", "errors", len(loadErrors))
+		program.Send(tui.DataReloadedMsg{Directory: store, LoadErrors: loadErrors})
+	})
+}
+
+// findDepartment looks a department up by Id or, failing that, by name ignoring case and diacritics
+func findDepartment(dir services.Directory, idOrName string) (*services.Department, error) {
+	if dept, err := dir.GetDepartmentById(idOrName); err == nil {
+		return dept, nil
+	}
+
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, err
+	}
+	name := services.NormalizeSearch(strings.TrimSpace(idOrName))
+	for i := range departments {
+		if services.NormalizeSearch(departments[i].Name) == name {
+			return &departments[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("department %q not found", idOrName)
+}
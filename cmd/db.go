@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the SQLite database",
+}
+
+var dbInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the database and apply migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		fmt.Println("Database ready:", databasePath())
+		return nil
+	},
+}
+
+var dbImportJSONCmd = &cobra.Command{
+	Use:   "import-json",
+	Short: "Import " + peopleFile + " and " + departmentsFile + " into the database",
+	Long:  "Replaces the contents of the database with the JSON data files found through data.dir or --data-dir",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := openJSONDirectory()
+		if err != nil {
+			return err
+		}
+
+		people, err := source.GetPeople()
+		if err != nil {
+			return err
+		}
+		departments, err := source.GetDepartments()
+		if err != nil {
+			return err
+		}
+
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := db.Import(people, departments); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d people and %d departments into %s\n", len(people), len(departments), databasePath())
+		return nil
+	},
+}
+
+// openDatabase opens the configured database, creating its directory when needed
+func openDatabase() (*services.SQLiteDirectory, error) {
+	path := databasePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	return services.OpenSQLite(path)
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbInitCmd)
+	dbCmd.AddCommand(dbImportJSONCmd)
+
+	dbCmd.PersistentFlags().String("database", "", "SQLite database path (default "+databaseFile+" in the data directory, then $XDG_DATA_HOME/"+cmdName+")")
+	viper.BindPFlag("data.database", dbCmd.PersistentFlags().Lookup("database"))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/htekgulds/terminal-rehber/pkg/output"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Show what changed between two copies of the directory",
+	Long:  "Compares two data snapshots, each a directory with people.json and departments.json or a SQLite database file, and reports the people and departments that were added, removed or modified, field by field. Records are matched by Id and databases are opened read-only. Exits with status 1 when there are differences.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		old, closeOld, err := openSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		defer closeOld()
+		new, closeNew, err := openSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+		defer closeNew()
+
+		diff, err := services.DiffDirectories(old, new)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if err := output.WriteDiff(out, format, diff); err != nil {
+			return err
+		}
+		if format == output.Table && diff.Empty() {
+			fmt.Fprintf(out, "%s No differences\n", theme.Tick)
+		}
+
+		if !diff.Empty() {
+			return errExitStatus
+		}
+		return nil
+	},
+}
+
+// openSnapshot opens a data directory or SQLite database file given on the
+// command line without changing it
+func openSnapshot(path string) (services.Directory, func() error, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%s does not exist", path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case info.IsDir():
+		dir := services.NewJSONDirectory(path)
+		for _, file := range []string{dir.PeoplePath, dir.DepartmentsPath} {
+			if _, err := os.Stat(file); err != nil {
+				return nil, nil, fmt.Errorf("%s is not a data directory: %s not found", path, filepath.Base(file))
+			}
+		}
+		return dir, func() error { return nil }, nil
+
+	case info.Mode().IsRegular():
+		db, err := services.OpenSQLiteReadOnly(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Close, nil
+	}
+	return nil, nil, fmt.Errorf("%s is neither a data directory nor a database file", path)
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	addOutputFlags(diffCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/htekgulds/terminal-rehber/pkg/vcard"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDept string
+	exportFile string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the directory for other applications",
+}
+
+var exportVCardCmd = &cobra.Command{
+	Use:   "vcard",
+	Short: "Export people as vCard 4.0",
+	Long:  "Writes one vCard per person, with the department hierarchy as ORG and the room and floor as a work address. With --dept only the people in that department and its sub-departments are exported.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadStore()
+		if err != nil {
+			return err
+		}
+
+		var people []services.Person
+		if exportDept != "" {
+			dept, err := findDepartment(store, exportDept)
+			if err != nil {
+				return err
+			}
+			descendants, err := services.Descendants(store, dept.Id)
+			if err != nil {
+				return err
+			}
+			for _, d := range append([]services.Department{*dept}, descendants...) {
+				members, err := store.GetPeopleByDepartmentId(d.Id)
+				if err != nil {
+					return err
+				}
+				people = append(people, members...)
+			}
+		} else if people, err = store.GetPeople(); err != nil {
+			return err
+		}
+		services.SortPeople(people)
+
+		cards := make([]vcard.Card, len(people))
+		for i, person := range people {
+			cards[i] = vcard.FromPerson(store, person)
+		}
+
+		if exportFile == "" || exportFile == "-" {
+			return vcard.Encode(cmd.OutOrStdout(), cards)
+		}
+
+		f, err := os.Create(exportFile)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", exportFile, err)
+		}
+		if err := vcard.Encode(f, cards); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", exportFile, err)
+		}
+		// Write errors may only surface when the file is closed
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", exportFile, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Exported %d people to %s\n", len(cards), exportFile)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportVCardCmd)
+
+	exportVCardCmd.Flags().StringVarP(&exportDept, "dept", "d", "", "only export a department and its sub-departments, by Id or name")
+	exportVCardCmd.Flags().StringVarP(&exportFile, "output", "o", "", "file to write, - or empty for standard output")
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/htekgulds/terminal-rehber/pkg/csvimport"
+	"github.com/htekgulds/terminal-rehber/pkg/vcard"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	importDryRun            bool
+	importOverwrite         bool
+	importMap               string
+	importProfile           string
+	importDelimiter         string
+	importCreateDepartments bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import people from other applications",
+}
+
+var importVCardCmd = &cobra.Command{
+	Use:   "vcard <file.vcf>",
+	Short: "Import people from vCards",
+	Long:  "Reads vCard 3.0 or 4.0 files and adds the people to the directory. Cards are matched to existing people by UID, then by name; a card that differs from its match is reported as a conflict and left alone unless --overwrite is given. Exits with status 1 when there are conflicts or rejected cards.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		cards, err := vcard.Decode(in)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		editor, close, err := openEditor()
+		if err != nil {
+			return err
+		}
+		defer close()
+
+		items := make([]services.ImportItem, len(cards))
+		for i, card := range cards {
+			person, warnings, err := vcard.ToPerson(editor, card)
+			items[i] = services.ImportItem{
+				Source:   fmt.Sprintf("card %d", i+1),
+				Person:   person,
+				Warnings: warnings,
+				Err:      err,
+			}
+		}
+
+		return runImport(cmd, editor, items, nil)
+	},
+}
+
+var importCSVCmd = &cobra.Command{
+	Use:   "csv <file.csv>",
+	Short: "Import people from a CSV export",
+	Long: `Reads people from a CSV file such as an HR spreadsheet export. Columns are mapped to fields with --map, e.g. --map firstName=Ad,lastName=Soyad,department=Birim, or with a profile saved in the config under import.csv.profiles; without either, columns named like the fields are used.
+
+Fields: ` + strings.Join(csvimport.Fields, ", ") + `. The department column holds a department Id or name. Existing people are matched by id, then by name, and only the mapped fields are updated. Exits with status 1 when there are conflicts or rejected rows.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mapping := csvimport.Mapping{}
+		if importProfile != "" {
+			profile := viper.GetStringMapString("import.csv.profiles." + importProfile)
+			if len(profile) == 0 {
+				return fmt.Errorf("mapping profile %q not found in config", importProfile)
+			}
+			maps.Copy(mapping, profile)
+		}
+		if importMap != "" {
+			flagMapping, err := csvimport.ParseMapping(importMap)
+			if err != nil {
+				return err
+			}
+			maps.Copy(mapping, flagMapping)
+		}
+
+		delimiter, size := utf8.DecodeRuneInString(importDelimiter)
+		if size == 0 || size != len(importDelimiter) {
+			return fmt.Errorf("delimiter must be a single character, got %q", importDelimiter)
+		}
+
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		editor, close, err := openEditor()
+		if err != nil {
+			return err
+		}
+		defer close()
+
+		items, departments, err := csvimport.Read(editor, in, csvimport.Options{
+			Mapping:           mapping,
+			Delimiter:         delimiter,
+			CreateDepartments: importCreateDepartments,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		return runImport(cmd, editor, items, departments)
+	},
+}
+
+// runImport plans the import of items, reports every result and applies it
+// unless --dry-run is given. newDepartments are created first when used.
+func runImport(cmd *cobra.Command, editor services.Editor, items []services.ImportItem, newDepartments []services.Department) error {
+	results, err := services.PlanImport(editor, items, newDepartments, importOverwrite)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	usedDepartments := services.UsedDepartments(newDepartments, results)
+	for _, dept := range usedDepartments {
+		fmt.Fprintf(out, "%s create    department %s\n", theme.Tick, dept.Name)
+	}
+	for _, result := range results {
+		name := result.Person.FullName()
+		switch result.Action {
+		case services.ImportCreate:
+			fmt.Fprintf(out, "%s create    %s (%s)\n", theme.Tick, name, result.Source)
+		case services.ImportUpdate:
+			fmt.Fprintf(out, "%s update    %s (%s)\n", theme.Tick, name, result.Source)
+		case services.ImportConflict:
+			fmt.Fprintf(out, "%s conflict  %s (%s): %s\n", theme.Cross, name, result.Source, result.Reason)
+		case services.ImportReject:
+			fmt.Fprintf(out, "%s reject    %s: %s\n", theme.Cross, result.Source, result.Reason)
+		default:
+			continue
+		}
+		for _, change := range result.Changes {
+			fmt.Fprintf(out, "    %s: %q → %q\n", change.Field, change.Old, change.New)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(out, "    warning: %s\n", warning)
+		}
+	}
+
+	counts := services.ImportCounts(results)
+	if len(usedDepartments) > 0 {
+		fmt.Fprintf(out, "\nNew departments: %d", len(usedDepartments))
+	}
+	fmt.Fprintf(out, "\n%d to create, %d to update, %d unchanged, %d conflicts, %d rejected\n",
+		counts[services.ImportCreate], counts[services.ImportUpdate], counts[services.ImportUnchanged],
+		counts[services.ImportConflict], counts[services.ImportReject])
+
+	if importDryRun {
+		fmt.Fprintln(out, "Dry run, nothing was written")
+	} else if err := services.ApplyImport(editor, newDepartments, results); err != nil {
+		return err
+	}
+
+	// The valid records are written either way; the status reports the others
+	if counts[services.ImportConflict] > 0 || counts[services.ImportReject] > 0 {
+		return errExitStatus
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importVCardCmd)
+	importCmd.AddCommand(importCSVCmd)
+
+	importCmd.PersistentFlags().BoolVarP(&importDryRun, "dry-run", "n", false, "only report what would change")
+	importCmd.PersistentFlags().BoolVar(&importOverwrite, "overwrite", false, "update existing people that differ instead of reporting a conflict")
+
+	importCSVCmd.Flags().StringVarP(&importMap, "map", "m", "", "column mapping as field=Column pairs, added to the profile")
+	importCSVCmd.Flags().StringVarP(&importProfile, "profile", "p", "", "mapping profile from import.csv.profiles in the config")
+	importCSVCmd.Flags().StringVar(&importDelimiter, "delimiter", ",", "value separator, e.g. ';' for spreadsheet exports in Turkish locales")
+	importCSVCmd.Flags().BoolVar(&importCreateDepartments, "create-departments", false, "create departments that are named in the file but do not exist")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"github.com/htekgulds/terminal-rehber/pkg/orgchart"
+	"github.com/spf13/cobra"
+)
+
+var (
+	orgchartDepth   int
+	orgchartMembers bool
+)
+
+var orgchartCmd = &cobra.Command{
+	Use:   "orgchart [dept]",
+	Short: "Draw the reporting structure of the departments",
+	Long:  "Draws departments with their managers and members, below the given department (Id or name) or for the whole organisation. The text and ascii formats draw boxes in the terminal; dot and mermaid can be pasted into Graphviz or a wiki.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := orgchartOutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		store, err := loadStore()
+		if err != nil {
+			return err
+		}
+
+		rootId := ""
+		if len(args) > 0 {
+			dept, err := findDepartment(store, args[0])
+			if err != nil {
+				return err
+			}
+			rootId = dept.Id
+		}
+
+		nodes, err := orgchart.Build(store, rootId, orgchart.Options{
+			Depth:   orgchartDepth,
+			Members: orgchartMembers,
+		})
+		if err != nil {
+			return err
+		}
+		style, err := phoneStyle()
+		if err != nil {
+			return err
+		}
+		return orgchart.Write(cmd.OutOrStdout(), format, nodes, style)
+	},
+}
+
+// orgchartOutputFormat returns the format given with --output, or with the
+// deprecated --format flag
+func orgchartOutputFormat(cmd *cobra.Command) (orgchart.Format, error) {
+	flag := "output"
+	if cmd.Flags().Changed("format") {
+		flag = "format"
+	}
+	name, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return "", err
+	}
+	return orgchart.ParseFormat(name)
+}
+
+func init() {
+	rootCmd.AddCommand(orgchartCmd)
+
+	// Org charts have their own formats, so the shared --output flag is defined here
+	orgchartCmd.Flags().StringP("output", "o", string(orgchart.Text), "output format: "+orgchart.FormatNames())
+	orgchartCmd.Flags().StringP("format", "f", string(orgchart.Text), "output format: "+orgchart.FormatNames())
+	orgchartCmd.Flags().MarkDeprecated("format", "use --output instead")
+	orgchartCmd.Flags().IntVar(&orgchartDepth, "depth", 0, "levels of sub-departments to draw (0 for all)")
+	orgchartCmd.Flags().BoolVar(&orgchartMembers, "members", true, "list the members of each department")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/htekgulds/terminal-rehber/pkg/output"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// addOutputFlags adds the shared --output flag to a command
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", string(output.Table), "output format: "+output.FormatNames())
+}
+
+// addTemplateFlags adds the --template and --dept-template flags to a command printing people and departments
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("template", "t", "", "render each person with a Go template, or the name of a template under templates: in the config file")
+	cmd.Flags().String("dept-template", "", "render each department with a Go template, or the name of a configured template")
+}
+
+// outputFormat returns the format selected with --output
+func outputFormat(cmd *cobra.Command) (output.Format, error) {
+	name, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	return output.ParseFormat(name)
+}
+
+// templateText returns the value of a template flag, replacing a configured
+// template name with its text. A value without actions must name a template.
+func templateText(cmd *cobra.Command, flag string) (string, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return "", nil
+	}
+	text, err := cmd.Flags().GetString(flag)
+	if err != nil || text == "" {
+		return text, err
+	}
+	if named := viper.GetString("templates." + text); named != "" {
+		return named, nil
+	}
+	if !strings.Contains(text, "{{") {
+		names := slices.Sorted(maps.Keys(viper.GetStringMap("templates")))
+		if len(names) == 0 {
+			return "", fmt.Errorf("unknown template %q, no templates are configured", text)
+		}
+		return "", fmt.Errorf("unknown template %q, expected a Go template or one of %s", text, strings.Join(names, ", "))
+	}
+	return text, nil
+}
+
+// writeRecords writes people and departments through --template and
+// --dept-template when either is given, leaving out the records without one,
+// otherwise in the --output format
+func writeRecords(cmd *cobra.Command, dir services.Directory, people []services.Person, departments []services.Department) error {
+	personText, err := templateText(cmd, "template")
+	if err != nil {
+		return err
+	}
+	deptText, err := templateText(cmd, "dept-template")
+	if err != nil {
+		return err
+	}
+	if personText != "" || deptText != "" {
+		var personTmpl, deptTmpl *template.Template
+		if personText != "" {
+			if personTmpl, err = output.NewTemplate(dir, personText); err != nil {
+				return err
+			}
+		}
+		if deptText != "" {
+			if deptTmpl, err = output.NewTemplate(dir, deptText); err != nil {
+				return err
+			}
+		}
+		return output.WriteTemplate(cmd.OutOrStdout(), personTmpl, deptTmpl, people, departments)
+	}
+
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	style, err := phoneStyle()
+	if err != nil {
+		return err
+	}
+	return output.Write(cmd.OutOrStdout(), format, dir, people, departments, style)
+}
@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/fang"
 	"github.com/htekgulds/terminal-rehber/pkg/tui"
+	"github.com/htekgulds/terminal-rehber/services"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -23,6 +27,10 @@ var rootCmd = &cobra.Command{
 	Use:   cmdName,
 	Short: "Terminal Rehber",
 	Long:  "Terminalde çalışan telefon rehberi uygulaması",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		_, err := phoneStyle()
+		return err
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log, err := os.Create("output.log")
 		if err != nil {
@@ -31,12 +39,28 @@ var rootCmd = &cobra.Command{
 		defer log.Close()
 		slog.SetDefault(slog.New(slog.NewTextHandler(log, &slog.HandlerOptions{})))
 
-		model, err := tui.NewModel()
+		store, loadErrors, err := loadAvailableStore()
 		if err != nil {
-			fmt.Println("Error creating model:", err)
+			fmt.Println("Error loading data:", err)
 			os.Exit(1)
 		}
-		if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+
+		style, err := phoneStyle()
+		if err != nil {
+			return err
+		}
+
+		model := tui.NewModel(store, loadErrors, style)
+		program := tea.NewProgram(model, tea.WithAltScreen())
+
+		watcher, err := watchData(program)
+		if err != nil {
+			slog.Warn("live reload disabled", "error", err)
+		} else {
+			defer watcher.Close()
+		}
+
+		if _, err := program.Run(); err != nil {
 			fmt.Println("Error running program:", err)
 			os.Exit(1)
 		}
@@ -44,8 +68,23 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// phoneStyle returns the display style set by --phone-style or phone.style in the config
+func phoneStyle() (services.PhoneStyle, error) {
+	return services.ParsePhoneStyle(viper.GetString("phone.style"))
+}
+
+// errExitStatus makes a command exit with status 1 without printing an error,
+// when it has already reported the outcome, e.g. differences found by diff.
+// Returning it instead of calling os.Exit lets deferred cleanup run.
+var errExitStatus = errors.New("exit status 1")
+
 func Execute() {
-	if err := fang.Execute(context.Background(), rootCmd); err != nil {
+	handleError := func(w io.Writer, styles fang.Styles, err error) {
+		if !errors.Is(err, errExitStatus) {
+			fang.DefaultErrorHandler(w, styles, err)
+		}
+	}
+	if err := fang.Execute(context.Background(), rootCmd, fang.WithErrorHandler(handleError)); err != nil {
 		os.Exit(1)
 	}
 }
@@ -55,9 +94,13 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file locations are: ./"+configFile+", $HOME/.config/"+cmdName+"/"+configFile+", /etc/"+cmdName+"/"+configFile)
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().String("phone-style", "", "how phone numbers are shown: raw, international, national, extension or e164 (default raw)")
+	rootCmd.PersistentFlags().String("data-dir", "", "directory containing "+peopleFile+" and "+departmentsFile+" (default ./data, then $XDG_DATA_HOME/"+cmdName+")")
 
 	// Bind flag to viper key
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("data.dir", rootCmd.PersistentFlags().Lookup("data-dir"))
+	viper.BindPFlag("phone.style", rootCmd.PersistentFlags().Lookup("phone-style"))
 }
 
 func initConfig() {
@@ -71,10 +114,11 @@ func initConfig() {
 	}
 
 	_ = godotenv.Load()
-	viper.AutomaticEnv()        // read env vars that match
-	viper.SetEnvPrefix(cmdName) // REHBER_ARGNAME=...
+	viper.AutomaticEnv()                                   // read env vars that match
+	viper.SetEnvPrefix(cmdName)                            // REHBER_ARGNAME=...
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_")) // data.dir -> REHBER_DATA_DIR
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
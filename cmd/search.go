@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchLimit int
+	searchDept  string
+	searchFloor int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search people and departments without starting the TUI",
+	Long:  "Searches people and departments with the same matching as the TUI and prints the results. Exits with status 1 when nothing matches.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Fail on a bad format before searching
+		if _, err := outputFormat(cmd); err != nil {
+			return err
+		}
+
+		store, err := loadStore()
+		if err != nil {
+			return err
+		}
+
+		opts := services.SearchOptions{Limit: searchLimit}
+		if searchDept != "" {
+			dept, err := findDepartment(store, searchDept)
+			if err != nil {
+				return err
+			}
+			opts.DepartmentId = dept.Id
+		}
+		if cmd.Flags().Changed("floor") {
+			opts.Floor = &searchFloor
+		}
+
+		results, err := services.Search(store, strings.Join(args, " "), opts)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(os.Stderr, "No matches found")
+			return errExitStatus
+		}
+
+		var people []services.Person
+		var departments []services.Department
+		for _, result := range results {
+			if result.Person != nil {
+				people = append(people, *result.Person)
+			} else {
+				departments = append(departments, *result.Department)
+			}
+		}
+
+		return writeRecords(cmd, store, people, departments)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", 0, "maximum number of results (0 for no limit)")
+	searchCmd.Flags().StringVarP(&searchDept, "dept", "d", "", "only search within a department and its sub-departments, by Id or name")
+	searchCmd.Flags().IntVar(&searchFloor, "floor", 0, "only show people on this floor")
+	addOutputFlags(searchCmd)
+	addTemplateFlags(searchCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/htekgulds/terminal-rehber/pkg/output"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+	"github.com/spf13/cobra"
+)
+
+var validateFormat string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the directory data for integrity problems",
+	Long:  "Reports dangling references, parent cycles, duplicate ids and phone numbers, managers outside their department and empty names. Exits with status 1 when any problem is found.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := validateOutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		store, err := loadStore()
+		if err != nil {
+			return err
+		}
+
+		people, _ := store.GetPeople()
+		departments, _ := store.GetDepartments()
+		problems := services.Validate(people, departments)
+
+		out := cmd.OutOrStdout()
+		if err := output.WriteProblems(out, format, problems); err != nil {
+			return err
+		}
+		if format == output.Table {
+			if len(problems) == 0 {
+				fmt.Fprintf(out, "%s %d people and %d departments, no problems found\n", theme.Tick, len(people), len(departments))
+			} else {
+				fmt.Fprintf(out, "\n%d problems found\n", len(problems))
+			}
+		}
+
+		if len(problems) > 0 {
+			return errExitStatus
+		}
+		return nil
+	},
+}
+
+// validateOutputFormat returns the --output format, or the one given with the
+// deprecated --format flag, where text means table
+func validateOutputFormat(cmd *cobra.Command) (output.Format, error) {
+	if !cmd.Flags().Changed("format") {
+		return outputFormat(cmd)
+	}
+	switch validateFormat {
+	case "text":
+		return output.Table, nil
+	case "json":
+		return output.JSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected text or json", validateFormat)
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	addOutputFlags(validateCmd)
+	validateCmd.Flags().StringVarP(&validateFormat, "format", "f", "text", "output format: text or json")
+	validateCmd.Flags().MarkDeprecated("format", "use --output instead")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/spf13/cobra"
+)
+
+var whoisCmd = &cobra.Command{
+	Use:   "whois <number>",
+	Short: "Find the person or department owning a phone number",
+	Long:  "Looks up a phone number in people and departments. Spaces, dashes, the +90 country code and a leading 0 are ignored, and a short number such as a 4-digit extension matches the numbers ending in it. Exits with status 1 when nothing matches.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Fail on a bad format before looking up
+		if _, err := outputFormat(cmd); err != nil {
+			return err
+		}
+
+		store, err := loadStore()
+		if err != nil {
+			return err
+		}
+
+		// Numbers are often pasted with spaces, which the shell splits
+		people, departments, err := services.LookupPhone(store, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		if len(people) == 0 && len(departments) == 0 {
+			fmt.Fprintln(os.Stderr, "No matches found")
+			return errExitStatus
+		}
+
+		return writeRecords(cmd, store, people, departments)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoisCmd)
+
+	addOutputFlags(whoisCmd)
+	addTemplateFlags(whoisCmd)
+}
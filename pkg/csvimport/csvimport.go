@@ -0,0 +1,263 @@
+// Package csvimport reads people from spreadsheet exports, mapping their
+// columns to person fields
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// Fields lists the fields a column can be mapped to. department takes a
+// department Id or name; mobile, fax and email become contact points.
+var Fields = []string{"id", "prefix", "firstName", "lastName", "title", "department", "room", "floor", "phone", "mobile", "fax", "email"}
+
+// Mapping maps fields to the column headers they are read from
+type Mapping map[string]string
+
+// ParseMapping reads a mapping written as field=Column pairs separated by commas,
+// e.g. "firstName=Ad,lastName=Soyad"
+func ParseMapping(s string) (Mapping, error) {
+	mapping := Mapping{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("mapping %q is not in field=Column form", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// field returns the field name in Fields matching name, ignoring case since
+// config keys are read in lower case
+func field(name string) (string, bool) {
+	i := slices.IndexFunc(Fields, func(f string) bool { return strings.EqualFold(f, name) })
+	if i < 0 {
+		return "", false
+	}
+	return Fields[i], true
+}
+
+// Options controls how a file is read
+type Options struct {
+	// Mapping maps fields to columns; when empty, columns named like the fields are used
+	Mapping Mapping
+	// Delimiter separates values, ',' when zero
+	Delimiter rune
+	// CreateDepartments adds departments named in the file that do not exist yet
+	CreateDepartments bool
+}
+
+// Read reads one import item per row of r, resolving departments through dir.
+// It also returns the departments to create when opts.CreateDepartments is set.
+// Rows that cannot be read become items with Err set, so they are reported
+// as rejected.
+func Read(dir services.Directory, r io.Reader, opts Options) ([]services.ImportItem, []services.Department, error) {
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet applications start UTF-8 files with a byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := mapColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, hasId := columns["id"]
+	_, hasFirst := columns["firstName"]
+	_, hasLast := columns["lastName"]
+	if !hasId && !(hasFirst && hasLast) {
+		return nil, nil, fmt.Errorf("firstName and lastName, or id, must be mapped to columns")
+	}
+
+	// Fields tells PlanImport what an update may change
+	fields := make([]string, 0, len(columns))
+	for _, f := range Fields {
+		if _, ok := columns[f]; !ok || f == "id" {
+			continue
+		}
+		if f == "department" {
+			f = "departmentId"
+		}
+		fields = append(fields, f)
+	}
+
+	resolver, err := newResolver(dir, opts.CreateDepartments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []services.ImportItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			items = append(items, services.ImportItem{Source: fmt.Sprintf("line %d", parseErr.StartLine), Err: err})
+			continue
+		}
+		if slices.IndexFunc(record, func(v string) bool { return strings.TrimSpace(v) != "" }) < 0 {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		values := make(map[string]string, len(columns))
+		for f, i := range columns {
+			if i < len(record) {
+				values[f] = strings.TrimSpace(record[i])
+			}
+		}
+		person, err := rowPerson(values, resolver)
+		items = append(items, services.ImportItem{
+			Source: fmt.Sprintf("line %d", line),
+			Person: person,
+			Fields: fields,
+			Err:    err,
+		})
+	}
+
+	return items, resolver.created, nil
+}
+
+// mapColumns returns the column index of every mapped field
+func mapColumns(header []string, mapping Mapping) (map[string]int, error) {
+	find := func(name string) int {
+		if i := slices.Index(header, name); i >= 0 {
+			return i
+		}
+		return slices.IndexFunc(header, func(h string) bool {
+			return services.NormalizeSearch(strings.TrimSpace(h)) == services.NormalizeSearch(name)
+		})
+	}
+
+	columns := make(map[string]int)
+	if len(mapping) == 0 {
+		for _, f := range Fields {
+			if i := find(f); i >= 0 {
+				columns[f] = i
+			}
+		}
+		return columns, nil
+	}
+
+	for name, column := range mapping {
+		f, ok := field(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(Fields, ", "))
+		}
+		i := find(column)
+		if i < 0 {
+			return nil, fmt.Errorf("column %q for %s not found in header", column, f)
+		}
+		columns[f] = i
+	}
+	return columns, nil
+}
+
+// rowPerson builds a person from the mapped values of a row
+func rowPerson(values map[string]string, resolver *resolver) (services.Person, error) {
+	person := services.Person{
+		Id:        values["id"],
+		FirstName: values["firstName"],
+		LastName:  values["lastName"],
+		Title:     values["title"],
+		Room:      values["room"],
+		Phone:     values["phone"],
+	}
+	if prefix := values["prefix"]; prefix != "" {
+		person.Prefix = &prefix
+	}
+	if floor := values["floor"]; floor != "" {
+		f, err := strconv.Atoi(floor)
+		if err != nil {
+			return person, fmt.Errorf("floor %q is not a number", floor)
+		}
+		person.Floor = f
+	}
+
+	for _, kind := range []services.ContactKind{services.ContactMobile, services.ContactFax, services.ContactEmail} {
+		if value := values[string(kind)]; value != "" {
+			person.Contacts = append(person.Contacts, services.ContactPoint{Kind: kind, Value: value})
+		}
+	}
+
+	if dept := values["department"]; dept != "" {
+		id, err := resolver.resolve(dept)
+		if err != nil {
+			return person, err
+		}
+		person.DepartmentId = id
+	}
+	return person, nil
+}
+
+// resolver finds departments by Id or name, planning new ones when allowed
+type resolver struct {
+	departments []services.Department
+	create      bool
+	created     []services.Department
+}
+
+func newResolver(dir services.Directory, create bool) (*resolver, error) {
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+	return &resolver{departments: departments, create: create}, nil
+}
+
+// resolve returns the Id of the department with the given Id or name
+func (r *resolver) resolve(value string) (string, error) {
+	for _, dept := range r.departments {
+		if dept.Id == value {
+			return dept.Id, nil
+		}
+	}
+
+	name := services.NormalizeSearch(value)
+	var matches []services.Department
+	for _, dept := range r.departments {
+		if services.NormalizeSearch(dept.Name) == name {
+			matches = append(matches, dept)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0].Id, nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("department name %q is ambiguous, use its Id", value)
+	case !r.create:
+		return "", fmt.Errorf("department %q not found", value)
+	}
+
+	dept := services.Department{Id: uuid.NewString(), Name: value}
+	r.departments = append(r.departments, dept)
+	r.created = append(r.created, dept)
+	return dept.Id, nil
+}
@@ -0,0 +1,126 @@
+package csvimport_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/pkg/csvimport"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+func testDirectory() *services.Store {
+	return services.NewStore(
+		[]services.Person{{Id: "ali", FirstName: "Ali", LastName: "Yılmaz", DepartmentId: "eng"}},
+		[]services.Department{
+			{Id: "eng", Name: "Bilgi İşlem"},
+			{Id: "hr1", Name: "İnsan Kaynakları"},
+			{Id: "hr2", Name: "Insan Kaynaklari"},
+		},
+	)
+}
+
+func TestReadHeaderErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		input   string
+		mapping csvimport.Mapping
+		want    string
+	}{
+		{"empty file", "", nil, "failed to read header"},
+		{"unknown field", "Ad,Soyad\n", csvimport.Mapping{"nickname": "Ad"}, `unknown field "nickname"`},
+		{"missing column", "Ad,Soyad\n", csvimport.Mapping{"firstName": "Adı", "lastName": "Soyad"}, `column "Adı" for firstName not found`},
+		{"no name columns", "title,room\nEngineer,A-1\n", nil, "must be mapped"},
+		{"only one name column", "Ad,Unvan\n", csvimport.Mapping{"firstName": "Ad", "title": "Unvan"}, "must be mapped"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := csvimport.Read(testDirectory(), strings.NewReader(tt.input), csvimport.Options{Mapping: tt.mapping})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStripsByteOrderMark(t *testing.T) {
+	input := "\ufefffirstName,lastName,room\nAyşe,Demir,A-205\n"
+	items, _, err := csvimport.Read(testDirectory(), strings.NewReader(input), csvimport.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Err != nil || items[0].Person.FirstName != "Ayşe" {
+		t.Fatalf("items are %+v, want Ayşe Demir", items)
+	}
+}
+
+func TestReadMapping(t *testing.T) {
+	input := "ADI;SOYADI;Birim;Kat;Cep;E-posta;Not\n" +
+		"Ayşe;Demir;Bilgi İşlem;2;+90-532-555-1002;ayse@example.com;x\n" +
+		"\n" +
+		"Can;Kaya;Bilgi İşlem;bir;;;\n" +
+		"Ece;Şahin;insan kaynaklari;;;;\n" +
+		"Fatma;Çelik;Yeni Birim;;;;\n" +
+		"Deniz;Ak;yeni birim;;;;\n"
+	mapping, err := csvimport.ParseMapping("firstName=Adı, lastName=Soyadı, department=Birim, floor=Kat, mobile=Cep, email=E-posta")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, created, err := csvimport.Read(testDirectory(), strings.NewReader(input), csvimport.Options{
+		Mapping:           mapping,
+		Delimiter:         ';',
+		CreateDepartments: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("got %d items, want 5 with the blank line skipped", len(items))
+	}
+
+	wantFields := []string{"firstName", "lastName", "departmentId", "floor", "mobile", "email"}
+	if !slices.Equal(items[0].Fields, wantFields) {
+		t.Errorf("fields are %v, want %v", items[0].Fields, wantFields)
+	}
+
+	ayse := items[0]
+	wantContacts := []services.ContactPoint{
+		{Kind: services.ContactMobile, Value: "+90-532-555-1002"},
+		{Kind: services.ContactEmail, Value: "ayse@example.com"},
+	}
+	if ayse.Err != nil || ayse.Source != "line 2" || ayse.Person.DepartmentId != "eng" || ayse.Person.Floor != 2 ||
+		!slices.Equal(ayse.Person.Contacts, wantContacts) {
+		t.Errorf("first item is %+v", ayse)
+	}
+
+	if err := items[1].Err; err == nil || !strings.Contains(err.Error(), `floor "bir" is not a number`) {
+		t.Errorf("item with a bad floor has error %v", err)
+	}
+	if err := items[2].Err; err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("item with an ambiguous department has error %v", err)
+	}
+
+	// Both spellings of the new department refer to the one planned department
+	if len(created) != 1 || created[0].Name != "Yeni Birim" {
+		t.Fatalf("created departments are %+v, want Yeni Birim", created)
+	}
+	for _, item := range items[3:] {
+		if item.Err != nil || item.Person.DepartmentId != created[0].Id {
+			t.Errorf("item is %+v, want it in the created department", item)
+		}
+	}
+}
+
+func TestReadMissingDepartment(t *testing.T) {
+	input := "firstName,lastName,department\nAyşe,Demir,Muhasebe\n"
+	items, created, err := csvimport.Read(testDirectory(), strings.NewReader(input), csvimport.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 0 {
+		t.Errorf("created departments %+v without CreateDepartments", created)
+	}
+	if len(items) != 1 || items[0].Err == nil || !strings.Contains(items[0].Err.Error(), `department "Muhasebe" not found`) {
+		t.Errorf("items are %+v, want one rejected for its department", items)
+	}
+}
@@ -0,0 +1,82 @@
+package orgchart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeDOT writes the chart as a Graphviz digraph. Departments are boxes and
+// members hang below their department as plain text nodes.
+func writeDOT(w io.Writer, nodes []*Node) error {
+	var b strings.Builder
+	b.WriteString("digraph orgchart {\n")
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [arrowhead=none];\n")
+
+	walk(nodes, func(node *Node) {
+		label := []string{node.Department.Name}
+		if node.Manager != nil {
+			label = append(label, "★ "+personLabel(*node.Manager))
+		}
+		fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(node.Department.Id), dotQuote(strings.Join(label, "\n")))
+
+		if len(node.Members) > 0 {
+			members := make([]string, len(node.Members))
+			for i, member := range node.Members {
+				members[i] = personLabel(member)
+			}
+			id := node.Department.Id + "/members"
+			fmt.Fprintf(&b, "  %s [shape=plaintext, label=%s];\n", dotQuote(id), dotQuote(strings.Join(members, "\n")))
+			fmt.Fprintf(&b, "  %s -> %s [style=dotted];\n", dotQuote(node.Department.Id), dotQuote(id))
+		}
+		for _, child := range node.Children {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(node.Department.Id), dotQuote(child.Department.Id))
+		}
+	})
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote returns s as a DOT string, with newlines as centered line breaks
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// writeMermaid writes the chart as a Mermaid flowchart
+func writeMermaid(w io.Writer, nodes []*Node) error {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	// Mermaid ids are kept short and safe; the labels carry the names
+	ids := make(map[*Node]string)
+	walk(nodes, func(node *Node) {
+		ids[node] = fmt.Sprintf("d%d", len(ids)+1)
+	})
+
+	walk(nodes, func(node *Node) {
+		label := []string{"<b>" + mermaidEscape(node.Department.Name) + "</b>"}
+		if node.Manager != nil {
+			label = append(label, "★ "+mermaidEscape(personLabel(*node.Manager)))
+		}
+		for _, member := range node.Members {
+			label = append(label, mermaidEscape(personLabel(member)))
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node], strings.Join(label, "<br/>"))
+		for _, child := range node.Children {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[node], ids[child])
+		}
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape replaces the characters that end a Mermaid label or start markup
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
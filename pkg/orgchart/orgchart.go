@@ -0,0 +1,165 @@
+// Package orgchart builds the reporting structure of departments and renders
+// it as terminal boxes, Graphviz DOT or Mermaid
+package orgchart
+
+import (
+	"fmt"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// Node is a department in the chart
+type Node struct {
+	Department services.Department
+	// Manager is nil when the ManagerId does not resolve
+	Manager *services.Person
+	// Members lists the people in the department, without the manager
+	Members  []services.Person
+	Children []*Node
+}
+
+// Options controls what Build includes
+type Options struct {
+	// Depth limits the levels below the roots, 0 for no limit
+	Depth int
+	// Members includes the people of each department besides the manager
+	Members bool
+}
+
+// Build returns the chart below the department rootId, or the whole
+// organisation when rootId is empty. Departments whose parent is missing are
+// shown as roots, and each department appears once even when parents form a cycle.
+func Build(dir services.Directory, rootId string, opts Options) ([]*Node, error) {
+	var roots []services.Department
+	if rootId != "" {
+		dept, err := dir.GetDepartmentById(rootId)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, *dept)
+	} else {
+		departments, err := dir.GetDepartments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load departments: %w", err)
+		}
+		roots = rootDepartments(departments)
+	}
+
+	visited := make(map[string]bool)
+	var build func(dept services.Department, level int) (*Node, error)
+	build = func(dept services.Department, level int) (*Node, error) {
+		visited[dept.Id] = true
+		node := &Node{Department: dept}
+		if manager, err := dir.GetPersonById(dept.ManagerId); err == nil {
+			node.Manager = manager
+		}
+
+		if opts.Members {
+			people, err := dir.GetPeopleByDepartmentId(dept.Id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load members of %s: %w", dept.Name, err)
+			}
+			services.SortPeople(people)
+			for _, person := range people {
+				if person.Id != dept.ManagerId {
+					node.Members = append(node.Members, person)
+				}
+			}
+		}
+
+		if opts.Depth > 0 && level >= opts.Depth {
+			return node, nil
+		}
+		children, err := dir.GetDepartmentsByParentId(dept.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sub-departments of %s: %w", dept.Name, err)
+		}
+		services.SortDepartments(children)
+		for _, child := range children {
+			if visited[child.Id] {
+				continue
+			}
+			childNode, err := build(child, level+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, childNode)
+		}
+		return node, nil
+	}
+
+	nodes := make([]*Node, 0, len(roots))
+	for _, dept := range roots {
+		if visited[dept.Id] {
+			continue
+		}
+		node, err := build(dept, 0)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// rootDepartments returns the departments whose parent is missing, followed
+// by the first department of each parent cycle, which no root leads to
+func rootDepartments(departments []services.Department) []services.Department {
+	services.SortDepartments(departments)
+	ids := make(map[string]bool, len(departments))
+	for _, dept := range departments {
+		ids[dept.Id] = true
+	}
+	var roots []services.Department
+	children := make(map[string][]string)
+	for _, dept := range departments {
+		if dept.ParentDepartmentId == nil || !ids[*dept.ParentDepartmentId] {
+			roots = append(roots, dept)
+		} else {
+			children[*dept.ParentDepartmentId] = append(children[*dept.ParentDepartmentId], dept.Id)
+		}
+	}
+
+	reached := make(map[string]bool)
+	var reach func(id string)
+	reach = func(id string) {
+		if reached[id] {
+			return
+		}
+		reached[id] = true
+		for _, child := range children[id] {
+			reach(child)
+		}
+	}
+	for _, dept := range roots {
+		reach(dept.Id)
+	}
+	for _, dept := range departments {
+		if !reached[dept.Id] {
+			roots = append(roots, dept)
+			reach(dept.Id)
+		}
+	}
+	return roots
+}
+
+// walk calls fn for every node, parents before their children
+func walk(nodes []*Node, fn func(node *Node)) {
+	for _, node := range nodes {
+		fn(node)
+		walk(node.Children, fn)
+	}
+}
+
+// personLabel returns the name followed by the title, if any
+func personLabel(person services.Person) string {
+	return personLabelSep(person, " · ")
+}
+
+// personLabelSep is personLabel with the given separator
+func personLabelSep(person services.Person, sep string) string {
+	if person.Title == "" {
+		return person.DisplayName()
+	}
+	return person.DisplayName() + sep + person.Title
+}
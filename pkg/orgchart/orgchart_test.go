@@ -0,0 +1,54 @@
+package orgchart
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// nodeIds lists the department Ids of nodes and their children, depth first
+func nodeIds(nodes []*Node) []string {
+	var ids []string
+	walk(nodes, func(node *Node) {
+		ids = append(ids, node.Department.Id)
+	})
+	return ids
+}
+
+func TestBuild(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	store := services.NewStore(nil, []services.Department{
+		{Id: "root", Name: "Root"},
+		{Id: "eng", Name: "Engineering", ParentDepartmentId: parent("root")},
+		{Id: "web", Name: "Web", ParentDepartmentId: parent("eng")},
+		{Id: "b", Name: "Loop B", ParentDepartmentId: parent("a")},
+		{Id: "a", Name: "Loop A", ParentDepartmentId: parent("b")},
+		{Id: "self", Name: "Self", ParentDepartmentId: parent("self")},
+		{Id: "orphan", Name: "Orphan", ParentDepartmentId: parent("missing")},
+	})
+
+	tests := []struct {
+		name   string
+		rootId string
+		depth  int
+		want   []string
+	}{
+		{"whole organisation", "", 0, []string{"orphan", "root", "eng", "web", "a", "b", "self"}},
+		{"below a department", "eng", 0, []string{"eng", "web"}},
+		{"inside a cycle", "b", 0, []string{"b", "a"}},
+		// Departments below the limit are left out, not promoted to roots
+		{"depth limit", "", 1, []string{"orphan", "root", "eng", "a", "b", "self"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := Build(store, tt.rootId, Options{Depth: tt.depth})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nodeIds(nodes); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
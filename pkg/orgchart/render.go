@@ -0,0 +1,177 @@
+package orgchart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+)
+
+// Format is an org chart output format
+type Format string
+
+const (
+	Text    Format = "text"
+	ASCII   Format = "ascii"
+	DOT     Format = "dot"
+	Mermaid Format = "mermaid"
+)
+
+// Formats lists every supported format
+var Formats = []Format{Text, ASCII, DOT, Mermaid}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown org chart format %q, expected one of %s", name, FormatNames())
+}
+
+// FormatNames returns the supported formats as "text|ascii|..."
+func FormatNames() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, "|")
+}
+
+// Write renders the chart to w in the given format, with department phone
+// numbers in phoneStyle
+func Write(w io.Writer, format Format, nodes []*Node, phoneStyle services.PhoneStyle) error {
+	switch format {
+	case Text:
+		style := unicodeStyle
+		style.phone = phoneStyle
+		return writeBoxes(w, nodes, style)
+	case ASCII:
+		style := asciiStyle
+		style.phone = phoneStyle
+		return writeBoxes(w, nodes, style)
+	case DOT:
+		return writeDOT(w, nodes)
+	case Mermaid:
+		return writeMermaid(w, nodes)
+	}
+	return fmt.Errorf("unknown org chart format %q", format)
+}
+
+// boxStyle holds the characters used to draw boxes and connectors
+type boxStyle struct {
+	border  lipgloss.Border
+	tee     string // replaces the left border where a connector joins the box
+	manager string
+	sep     string // between a name and a title
+	pipe    string
+	branch  string
+	last    string
+	space   string
+	phone   services.PhoneStyle
+}
+
+var unicodeStyle = boxStyle{
+	border:  lipgloss.RoundedBorder(),
+	tee:     "┤",
+	manager: "★",
+	sep:     " · ",
+	pipe:    "│  ",
+	branch:  "├──",
+	last:    "└──",
+	space:   "   ",
+}
+
+var asciiStyle = boxStyle{
+	border:  lipgloss.ASCIIBorder(),
+	tee:     "+",
+	manager: "*",
+	sep:     " - ",
+	pipe:    "|  ",
+	branch:  "+--",
+	last:    "`--",
+	space:   "   ",
+}
+
+// writeBoxes draws each department as a box, with sub-departments hanging
+// below their parent
+func writeBoxes(w io.Writer, nodes []*Node, style boxStyle) error {
+	var b strings.Builder
+	for i, node := range nodes {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range box(node, style) {
+			b.WriteString(line + "\n")
+		}
+		writeChildren(&b, node.Children, "  ", style)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeChildren draws the boxes of children, connected to a line starting at indent
+func writeChildren(b *strings.Builder, children []*Node, indent string, style boxStyle) {
+	for i, child := range children {
+		last := i == len(children)-1
+		lines := box(child, style)
+		for n, line := range lines {
+			// The connector joins the box at the department name
+			switch {
+			case n == 0:
+				b.WriteString(indent + style.pipe + line)
+			case n == 1 && last:
+				b.WriteString(indent + style.last + style.tee + trimFirst(line))
+			case n == 1:
+				b.WriteString(indent + style.branch + style.tee + trimFirst(line))
+			case last:
+				b.WriteString(indent + style.space + line)
+			default:
+				b.WriteString(indent + style.pipe + line)
+			}
+			b.WriteString("\n")
+		}
+
+		next := indent + style.pipe + "  "
+		if last {
+			next = indent + style.space + "  "
+		}
+		writeChildren(b, child.Children, next, style)
+	}
+}
+
+// box renders a department box and splits it into lines
+func box(node *Node, style boxStyle) []string {
+	lines := []string{theme.B.Render(node.Department.Name)}
+	if node.Department.Phone != "" {
+		lines = append(lines, theme.Text.Render(node.Department.FormattedPhone(style.phone)))
+	}
+	if node.Manager != nil {
+		lines = append(lines, theme.Text.Render(style.manager+" "+personLabelSep(*node.Manager, style.sep)))
+	} else if node.Department.ManagerId != "" {
+		lines = append(lines, theme.Text.Render(style.manager+" missing manager "+node.Department.ManagerId))
+	}
+	for _, member := range node.Members {
+		lines = append(lines, theme.Text.Render("  "+personLabelSep(member, style.sep)))
+	}
+
+	rendered := lipgloss.NewStyle().
+		Border(style.border).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+	return strings.Split(rendered, "\n")
+}
+
+// trimFirst drops the first rune of a box line, the left border
+func trimFirst(line string) string {
+	for i := range line {
+		if i > 0 {
+			return line[i:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+)
+
+// diffHeaders are the CSV and TSV headers of a diff, using the JSON field names.
+// Modified records have one row per changed field.
+var diffHeaders = []string{"entity", "change", "id", "name", "field", "old", "new", "oldName", "newName"}
+
+// WriteDiff renders the difference between two directories to w in the given
+// format. The table format lists each record on one line, e.g.
+// "~ Ayşe Demir: room A-205 → A-210", and writes nothing when there are no
+// differences.
+func WriteDiff(w io.Writer, format Format, diff *services.DirectoryDiff) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, diff)
+
+	case YAML:
+		return writeYAML(w, diff)
+
+	case CSV, TSV:
+		cw := newCSVWriter(w, format)
+		cw.Write(diffHeaders)
+		for _, section := range []struct {
+			entity string
+			diffs  services.RecordDiffs
+		}{{"person", diff.People}, {"department", diff.Departments}} {
+			for _, added := range section.diffs.Added {
+				cw.Write([]string{section.entity, "added", added.Id, added.Name, "", "", "", "", ""})
+			}
+			for _, removed := range section.diffs.Removed {
+				cw.Write([]string{section.entity, "removed", removed.Id, removed.Name, "", "", "", "", ""})
+			}
+			for _, modified := range section.diffs.Modified {
+				for _, c := range modified.Changes {
+					cw.Write([]string{section.entity, "modified", modified.Id, modified.Name, c.Field, c.Old, c.New, c.OldName, c.NewName})
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case Table:
+		var b strings.Builder
+		writeRecordDiffs(&b, "People", diff.People)
+		writeRecordDiffs(&b, "Departments", diff.Departments)
+		_, err := io.WriteString(w, b.String())
+		return err
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeRecordDiffs writes one section of the table diff, e.g. "Ayşe Demir: room A-205 → A-210"
+func writeRecordDiffs(b *strings.Builder, title string, diffs services.RecordDiffs) {
+	if diffs.Count() == 0 {
+		return
+	}
+	fmt.Fprintln(b, theme.B.Render(title))
+	for _, added := range diffs.Added {
+		fmt.Fprintf(b, "  + %s\n", added.Name)
+	}
+	for _, removed := range diffs.Removed {
+		fmt.Fprintf(b, "  - %s\n", removed.Name)
+	}
+	for _, modified := range diffs.Modified {
+		parts := make([]string, len(modified.Changes))
+		for i, change := range modified.Changes {
+			parts[i] = describeChange(change)
+		}
+		fmt.Fprintf(b, "  ~ %s: %s\n", modified.Name, strings.Join(parts, ", "))
+	}
+	fmt.Fprintf(b, "  %d added, %d removed, %d modified\n\n", len(diffs.Added), len(diffs.Removed), len(diffs.Modified))
+}
+
+// describeChange renders a field change, naming referenced records instead of showing their Ids
+func describeChange(change services.FieldChange) string {
+	value := func(v, name string) string {
+		switch {
+		case name != "":
+			return name
+		case v == "":
+			return "(none)"
+		}
+		return v
+	}
+
+	old, new := value(change.Old, change.OldName), value(change.New, change.NewName)
+	switch change.Field {
+	case "departmentId":
+		return fmt.Sprintf("moved %s → %s", old, new)
+	case "parentDepartmentId":
+		return fmt.Sprintf("parent %s → %s", old, new)
+	case "managerId":
+		return fmt.Sprintf("manager %s → %s", old, new)
+	}
+	return fmt.Sprintf("%s %s → %s", change.Field, old, new)
+}
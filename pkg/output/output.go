@@ -0,0 +1,210 @@
+// Package output renders people and departments for the non-interactive commands
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/theme"
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	TSV   Format = "tsv"
+)
+
+// Formats lists every supported format
+var Formats = []Format{Table, JSON, YAML, CSV, TSV}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, expected one of %s", name, FormatNames())
+}
+
+// FormatNames returns the supported formats as "table|json|..."
+func FormatNames() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, "|")
+}
+
+// CSV and TSV headers use the JSON field names
+var (
+	personHeaders     = []string{"id", "prefix", "firstName", "lastName", "title", "departmentName", "managerName", "room", "floor", "phone", "phoneE164", "extension", "contacts"}
+	departmentHeaders = []string{"id", "name", "parentDepartmentName", "managerName", "phone", "phoneE164", "extension"}
+)
+
+// Write renders people and departments to w in the given format, resolving
+// department, manager and parent names through dir. Tables show phone numbers
+// in phoneStyle; the other formats keep them as entered.
+func Write(w io.Writer, format Format, dir services.Directory, people []services.Person, departments []services.Department, phoneStyle services.PhoneStyle) error {
+	doc := NewDocument(dir, people, departments)
+
+	switch format {
+	case JSON:
+		return writeJSON(w, doc)
+
+	case YAML:
+		return writeYAML(w, doc)
+
+	case CSV, TSV:
+		return writeDelimited(w, format, doc)
+
+	case Table:
+		return writeTable(w, doc, phoneStyle)
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeJSON writes v as indented JSON without HTML escaping
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML writes v as YAML indented by two spaces
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// newCSVWriter returns a CSV writer, using tabs for TSV
+func newCSVWriter(w io.Writer, format Format) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if format == TSV {
+		cw.Comma = '\t'
+	}
+	return cw
+}
+
+func personRow(p PersonRecord) []string {
+	return []string{p.Id, p.Prefix, p.FirstName, p.LastName, p.Title, p.DepartmentName, p.ManagerName, p.Room, strconv.Itoa(p.Floor), p.Phone, phoneE164(p.PhoneNumber), phoneExtension(p.PhoneNumber), contactList(p)}
+}
+
+// contactList joins the contact points other than the phone, however it is
+// written, as "label: value", marking the primary one
+func contactList(p PersonRecord) string {
+	phone := services.NationalNumber(p.Phone)
+	var contacts []string
+	for _, c := range p.Contacts {
+		if c.Kind.IsPhone() && services.NationalNumber(c.Value) == phone {
+			continue
+		}
+		contact := c.Name() + ": " + c.Value
+		if c.Primary {
+			contact += " (primary)"
+		}
+		contacts = append(contacts, contact)
+	}
+	return strings.Join(contacts, "; ")
+}
+
+func departmentRow(d DepartmentRecord) []string {
+	return []string{d.Id, d.Name, d.ParentDepartmentName, d.ManagerName, d.Phone, phoneE164(d.PhoneNumber), phoneExtension(d.PhoneNumber)}
+}
+
+// phoneE164 returns the E.164 form of a parsed phone number, or "" when it was not parsed
+func phoneE164(phone *services.PhoneNumber) string {
+	if phone == nil {
+		return ""
+	}
+	return phone.E164()
+}
+
+// phoneExtension returns the internal extension of a parsed phone number, or "" when it was not parsed
+func phoneExtension(phone *services.PhoneNumber) string {
+	if phone == nil {
+		return ""
+	}
+	return phone.InternalExtension()
+}
+
+// writeDelimited writes people and departments as separate blocks separated by a blank line
+func writeDelimited(w io.Writer, format Format, doc Document) error {
+	cw := newCSVWriter(w, format)
+
+	if len(doc.People) > 0 || len(doc.Departments) == 0 {
+		cw.Write(personHeaders)
+		for _, p := range doc.People {
+			cw.Write(personRow(p))
+		}
+	}
+	if len(doc.Departments) > 0 {
+		if len(doc.People) > 0 {
+			cw.Flush()
+			fmt.Fprintln(w)
+		}
+		cw.Write(departmentHeaders)
+		for _, d := range doc.Departments {
+			cw.Write(departmentRow(d))
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTable writes people and departments as bordered tables styled with the theme
+func writeTable(w io.Writer, doc Document, phoneStyle services.PhoneStyle) error {
+	var tables []string
+
+	if len(doc.People) > 0 {
+		// Ids are left out and names combined to keep the table readable
+		t := newTable().Headers("Name", "Title", "Department", "Manager", "Room", "Floor", "Phone")
+		for _, p := range doc.People {
+			t.Row(p.FullName, p.Title, p.DepartmentName, p.ManagerName, p.Room, strconv.Itoa(p.Floor), services.FormatPhone(p.Phone, phoneStyle))
+		}
+		tables = append(tables, t.Render())
+	}
+	if len(doc.Departments) > 0 {
+		t := newTable().Headers("Name", "Parent", "Manager", "Phone")
+		for _, d := range doc.Departments {
+			t.Row(d.Name, d.ParentDepartmentName, d.ManagerName, services.FormatPhone(d.Phone, phoneStyle))
+		}
+		tables = append(tables, t.Render())
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(tables, "\n\n"))
+	return err
+}
+
+func newTable() *table.Table {
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(theme.Text.Faint(true)).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.Text.Padding(0, 1)
+		})
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// ProblemsDocument is the top-level structure of JSON and YAML validation output
+type ProblemsDocument struct {
+	Count    int                `json:"count" yaml:"count"`
+	Problems []services.Problem `json:"problems" yaml:"problems"`
+}
+
+// problemHeaders are the CSV and TSV headers of problems, using the JSON field names
+var problemHeaders = []string{"kind", "entity", "id", "field", "value", "message"}
+
+// WriteProblems renders validation problems to w in the given format. The
+// table format writes nothing when there are no problems.
+func WriteProblems(w io.Writer, format Format, problems []services.Problem) error {
+	// An empty list is kept as [] so the schema does not change with the results
+	doc := ProblemsDocument{Count: len(problems), Problems: make([]services.Problem, 0, len(problems))}
+	doc.Problems = append(doc.Problems, problems...)
+
+	switch format {
+	case JSON:
+		return writeJSON(w, doc)
+
+	case YAML:
+		return writeYAML(w, doc)
+
+	case CSV, TSV:
+		cw := newCSVWriter(w, format)
+		cw.Write(problemHeaders)
+		for _, p := range problems {
+			cw.Write([]string{string(p.Kind), p.Entity, p.Id, p.Field, p.Value, p.Message})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case Table:
+		if len(problems) == 0 {
+			return nil
+		}
+		t := newTable().Headers("Kind", "Entity", "Id", "Problem")
+		for _, p := range problems {
+			t.Row(string(p.Kind), p.Entity, p.Id, p.Message)
+		}
+		_, err := fmt.Fprintln(w, t.Render())
+		return err
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import "github.com/htekgulds/terminal-rehber/services"
+
+// PersonRecord is the exported form of a person with its references resolved
+type PersonRecord struct {
+	Id        string `json:"id" yaml:"id"`
+	Prefix    string `json:"prefix" yaml:"prefix"`
+	FirstName string `json:"firstName" yaml:"firstName"`
+	LastName  string `json:"lastName" yaml:"lastName"`
+	FullName  string `json:"fullName" yaml:"fullName"`
+	Title     string `json:"title" yaml:"title"`
+	Room      string `json:"room" yaml:"room"`
+	Floor     int    `json:"floor" yaml:"floor"`
+	Phone     string `json:"phone" yaml:"phone"`
+	// PhoneNumber is the parsed phone number, nil when it cannot be parsed
+	PhoneNumber *services.PhoneNumber `json:"phoneNumber,omitempty" yaml:"phoneNumber,omitempty"`
+	// Contacts lists every channel, including the phone, for people with more than one
+	Contacts       []services.ContactPoint `json:"contacts,omitempty" yaml:"contacts,omitempty"`
+	DepartmentId   string                  `json:"departmentId" yaml:"departmentId"`
+	DepartmentName string                  `json:"departmentName" yaml:"departmentName"`
+	ManagerId      string                  `json:"managerId" yaml:"managerId"`
+	ManagerName    string                  `json:"managerName" yaml:"managerName"`
+}
+
+// DepartmentRecord is the exported form of a department with its references resolved
+type DepartmentRecord struct {
+	Id                   string                `json:"id" yaml:"id"`
+	Name                 string                `json:"name" yaml:"name"`
+	Phone                string                `json:"phone" yaml:"phone"`
+	PhoneNumber          *services.PhoneNumber `json:"phoneNumber,omitempty" yaml:"phoneNumber,omitempty"`
+	ManagerId            string                `json:"managerId" yaml:"managerId"`
+	ManagerName          string                `json:"managerName" yaml:"managerName"`
+	ParentDepartmentId   string                `json:"parentDepartmentId" yaml:"parentDepartmentId"`
+	ParentDepartmentName string                `json:"parentDepartmentName" yaml:"parentDepartmentName"`
+}
+
+// Document is the top-level structure of JSON and YAML output
+type Document struct {
+	People      []PersonRecord     `json:"people" yaml:"people"`
+	Departments []DepartmentRecord `json:"departments" yaml:"departments"`
+}
+
+// NewPersonRecord builds a person record, resolving the department and its manager through dir
+func NewPersonRecord(dir services.Directory, person services.Person) PersonRecord {
+	record := PersonRecord{
+		Id:           person.Id,
+		FirstName:    person.FirstName,
+		LastName:     person.LastName,
+		FullName:     person.DisplayName(),
+		Title:        person.Title,
+		Room:         person.Room,
+		Floor:        person.Floor,
+		Phone:        person.Phone,
+		DepartmentId: person.DepartmentId,
+	}
+	if person.Prefix != nil {
+		record.Prefix = *person.Prefix
+	}
+	if phone, err := person.PhoneNumber(); err == nil {
+		record.PhoneNumber = &phone
+	}
+	if len(person.Contacts) > 0 {
+		record.Contacts = person.ContactPoints()
+	}
+
+	if dept, err := dir.GetDepartmentById(person.DepartmentId); err == nil {
+		record.DepartmentName = dept.Name
+		record.ManagerId = dept.ManagerId
+		if manager, err := dir.GetPersonById(dept.ManagerId); err == nil {
+			record.ManagerName = manager.DisplayName()
+		}
+	}
+
+	return record
+}
+
+// NewDepartmentRecord builds a department record, resolving the manager and parent through dir
+func NewDepartmentRecord(dir services.Directory, dept services.Department) DepartmentRecord {
+	record := DepartmentRecord{
+		Id:        dept.Id,
+		Name:      dept.Name,
+		Phone:     dept.Phone,
+		ManagerId: dept.ManagerId,
+	}
+	if phone, err := dept.PhoneNumber(); err == nil {
+		record.PhoneNumber = &phone
+	}
+
+	if manager, err := dir.GetPersonById(dept.ManagerId); err == nil {
+		record.ManagerName = manager.DisplayName()
+	}
+	if dept.ParentDepartmentId != nil {
+		record.ParentDepartmentId = *dept.ParentDepartmentId
+		if parent, err := dir.GetDepartmentById(*dept.ParentDepartmentId); err == nil {
+			record.ParentDepartmentName = parent.Name
+		}
+	}
+
+	return record
+}
+
+// NewDocument builds the output document for people and departments
+func NewDocument(dir services.Directory, people []services.Person, departments []services.Department) Document {
+	// Empty lists are kept as [] so the schema does not change with the results
+	doc := Document{
+		People:      make([]PersonRecord, 0, len(people)),
+		Departments: make([]DepartmentRecord, 0, len(departments)),
+	}
+	for _, person := range people {
+		doc.People = append(doc.People, NewPersonRecord(dir, person))
+	}
+	for _, dept := range departments {
+		doc.Departments = append(doc.Departments, NewDepartmentRecord(dir, dept))
+	}
+	return doc
+}
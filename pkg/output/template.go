@@ -0,0 +1,176 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// NewTemplate parses text as a per-record template. Records are passed as
+// services.Person or services.Department values, see WriteTemplate, and the
+// template can use these helpers, resolving references through dir:
+//
+//	fullName      name with prefix for a person, name for a department
+//	extension     internal extension of a phone number, person or department
+//	phone         phone number of a person or department in a style, e.g. {{phone "e164" .}}
+//	deptName      department name for a person or department Id
+//	managerOf     manager of a person's department or of a department, or nil
+//	isPerson      whether the record is a person
+//	isDepartment  whether the record is a department
+//	upper, lower  change case with Turkish rules, so i becomes İ and I becomes ı
+func NewTemplate(dir services.Directory, text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"fullName": func(v any) string {
+			switch v := v.(type) {
+			case services.Person:
+				return v.DisplayName()
+			case *services.Person:
+				return v.DisplayName()
+			case services.Department:
+				return v.Name
+			case *services.Department:
+				return v.Name
+			}
+			return fmt.Sprint(v)
+		},
+		"extension": func(v any) string {
+			return extension(phoneOf(v))
+		},
+		"phone": func(style string, v any) (string, error) {
+			phoneStyle, err := services.ParsePhoneStyle(style)
+			if err != nil {
+				return "", err
+			}
+			return services.FormatPhone(phoneOf(v), phoneStyle), nil
+		},
+		"deptName": func(v any) string {
+			id := fmt.Sprint(v)
+			switch v := v.(type) {
+			case services.Person:
+				id = v.DepartmentId
+			case *services.Person:
+				id = v.DepartmentId
+			}
+			if dept, err := dir.GetDepartmentById(id); err == nil {
+				return dept.Name
+			}
+			return ""
+		},
+		"managerOf": func(v any) *services.Person {
+			var managerId string
+			switch v := v.(type) {
+			case services.Person:
+				if dept, err := dir.GetDepartmentById(v.DepartmentId); err == nil {
+					managerId = dept.ManagerId
+				}
+			case *services.Person:
+				if dept, err := dir.GetDepartmentById(v.DepartmentId); err == nil {
+					managerId = dept.ManagerId
+				}
+			case services.Department:
+				managerId = v.ManagerId
+			case *services.Department:
+				managerId = v.ManagerId
+			}
+			if manager, err := dir.GetPersonById(managerId); err == nil {
+				return manager
+			}
+			return nil
+		},
+		"isPerson": func(v any) bool {
+			switch v.(type) {
+			case services.Person, *services.Person:
+				return true
+			}
+			return false
+		},
+		"isDepartment": func(v any) bool {
+			switch v.(type) {
+			case services.Department, *services.Department:
+				return true
+			}
+			return false
+		},
+		"upper": func(s string) string { return strings.ToUpperSpecial(unicode.TurkishCase, s) },
+		"lower": func(s string) string { return strings.ToLowerSpecial(unicode.TurkishCase, s) },
+	}
+
+	return template.New("record").Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// WriteTemplate renders every person through personTmpl, then every
+// department through deptTmpl, ending each record with a newline unless the
+// template already does. Records whose template is nil are left out. Nothing
+// is written when a template fails on any record.
+func WriteTemplate(w io.Writer, personTmpl, deptTmpl *template.Template, people []services.Person, departments []services.Department) error {
+	var buf bytes.Buffer
+	render := func(tmpl *template.Template, record any, name string) error {
+		start := buf.Len()
+		if err := tmpl.Execute(&buf, record); err != nil {
+			return fmt.Errorf("template failed for %s: %w", name, err)
+		}
+		if !bytes.HasSuffix(buf.Bytes()[start:], []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		return nil
+	}
+
+	if personTmpl != nil {
+		for _, person := range people {
+			if err := render(personTmpl, person, person.DisplayName()); err != nil {
+				return err
+			}
+		}
+	}
+	if deptTmpl != nil {
+		for _, dept := range departments {
+			if err := render(deptTmpl, dept, dept.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// phoneOf returns the phone number of a person or department, or v itself as a number
+func phoneOf(v any) string {
+	switch v := v.(type) {
+	case services.Person:
+		return v.Phone
+	case *services.Person:
+		return v.Phone
+	case services.Department:
+		return v.Phone
+	case *services.Department:
+		return v.Phone
+	}
+	return fmt.Sprint(v)
+}
+
+// extension returns the internal extension of a phone number, e.g. 1002 for
+// +90-212-555-1002, or the last group of digits when it cannot be parsed
+func extension(phone string) string {
+	if p, err := services.ParsePhone(phone); err == nil {
+		return p.InternalExtension()
+	}
+
+	phone = strings.TrimRight(phone, " ")
+	end := len(phone)
+	start := end
+	for start > 0 && phone[start-1] >= '0' && phone[start-1] <= '9' {
+		start--
+	}
+	digits := phone[start:end]
+	// Numbers written without separators fall back to the last four digits
+	if start == 0 && len(digits) > 4 {
+		return digits[len(digits)-4:]
+	}
+	return digits
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// DepartmentDetailModel lists the members and sub-departments of a department
+// and lets the user drill down and back up the hierarchy
+type DepartmentDetailModel struct {
+	dir        services.Directory
+	phoneStyle services.PhoneStyle
+	dept       services.Department
+	children   []services.Department
+	members    []services.Person // the manager, when a member, comes first
+	cursor     int               // index into children, then members
+	width      int
+	height     int
+}
+
+// NewDepartmentDetailModel creates a drill-down view for dept, showing phone numbers in phoneStyle
+func NewDepartmentDetailModel(dir services.Directory, dept services.Department, phoneStyle services.PhoneStyle, width, height int) *DepartmentDetailModel {
+	m := &DepartmentDetailModel{
+		dir:        dir,
+		phoneStyle: phoneStyle,
+		width:      width,
+		height:     height,
+	}
+	m.show(dept)
+	return m
+}
+
+// show switches the view to dept
+func (m *DepartmentDetailModel) show(dept services.Department) {
+	m.dept = dept
+	m.cursor = 0
+
+	m.children, _ = m.dir.GetDepartmentsByParentId(dept.Id)
+	services.SortDepartments(m.children)
+
+	members, _ := m.dir.GetPeopleByDepartmentId(dept.Id)
+	services.SortPeople(members)
+	m.members = nil
+	for _, member := range members {
+		if member.Id == dept.ManagerId {
+			m.members = append([]services.Person{member}, m.members...)
+		} else {
+			m.members = append(m.members, member)
+		}
+	}
+}
+
+// Init initializes the model
+func (m *DepartmentDetailModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model
+func (m *DepartmentDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.children)+len(m.members)-1 {
+				m.cursor++
+			}
+		case "enter":
+			// Only sub-departments can be drilled into
+			if m.cursor < len(m.children) {
+				m.show(m.children[m.cursor])
+			}
+		case "backspace":
+			if m.dept.ParentDepartmentId == nil {
+				return m, closeDetail
+			}
+			parent, err := m.dir.GetDepartmentById(*m.dept.ParentDepartmentId)
+			if err != nil {
+				return m, closeDetail
+			}
+			// Keep the department we came from selected in its parent
+			from := m.dept.Id
+			m.show(*parent)
+			for i := range m.children {
+				if m.children[i].Id == from {
+					m.cursor = i
+				}
+			}
+		case "esc":
+			return m, closeDetail
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the UI
+func (m *DepartmentDetailModel) View() string {
+	faint := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	current := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229"))
+	section := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62")).MarginTop(1)
+	managerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+
+	// Breadcrumbs from the top-level department, with the current one emphasized
+	path := departmentPath(m.dir, m.dept)
+	crumbs := faint.Render(strings.Join(path[:len(path)-1], " › "))
+	if len(path) > 1 {
+		crumbs += faint.Render(" › ")
+	}
+	crumbs += current.Render(path[len(path)-1])
+
+	manager := missingReference(m.dept.ManagerId)
+	if person, err := m.dir.GetPersonById(m.dept.ManagerId); err == nil {
+		manager = fmt.Sprintf("%s (%s)", person.DisplayName(), person.FormattedPhone(m.phoneStyle))
+	}
+
+	lines := []string{
+		crumbs,
+		"",
+		faint.Render("Phone    ") + m.dept.FormattedPhone(m.phoneStyle),
+		faint.Render("Manager  ") + managerStyle.Render(manager),
+	}
+	header := len(lines)
+
+	var rows []string
+	rows = append(rows, section.Render(fmt.Sprintf("Sub-departments (%d)", len(m.children))))
+	if len(m.children) == 0 {
+		rows = append(rows, faint.Render("none"))
+	}
+	for i, child := range m.children {
+		line := fmt.Sprintf(" ▸ %-40s %s ", child.Name, child.FormattedPhone(m.phoneStyle))
+		if i == m.cursor {
+			line = selected.Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	rows = append(rows, section.Render(fmt.Sprintf("Members (%d)", len(m.members))))
+	if len(m.members) == 0 {
+		rows = append(rows, faint.Render("none"))
+	}
+	for i, member := range m.members {
+		marker := "  "
+		if member.Id == m.dept.ManagerId {
+			marker = "★ "
+		}
+		line := fmt.Sprintf(" %s%-35s %-25s %s ", marker, member.DisplayName(), member.Title, member.FormattedPhone(m.phoneStyle))
+		switch {
+		case len(m.children)+i == m.cursor:
+			line = selected.Render(line)
+		case member.Id == m.dept.ManagerId:
+			line = managerStyle.Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	// Keep the selected row on screen when the lists are long
+	visible := max(m.height-header-14, 5)
+	cursorRow := m.cursor + 1
+	if m.cursor >= len(m.children) {
+		cursorRow += 1 + max(len(m.children), 1) - len(m.children)
+	}
+	start := max(0, min(cursorRow-visible/2, len(rows)-visible))
+	end := min(len(rows), start+visible)
+	lines = append(lines, rows[start:end]...)
+
+	lines = append(lines, lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Render("↑/↓: Navigate • Enter: Open sub-department • Backspace: Up • Esc: Close"))
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(m.width - 4)
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -11,53 +11,38 @@ import (
 
 // DepartmentsModel represents the departments table model
 type DepartmentsModel struct {
-	table table.Model
-	ready bool
+	dir         services.Directory
+	phoneStyle  services.PhoneStyle
+	table       table.Model
+	all         []services.Department
+	departments []services.Department // departments in table order
+	treeMode    bool
+	tree        []treeNode
+	expanded    map[string]bool
+	detail      *DepartmentDetailModel
+	width       int
+	height      int
+	ready       bool
 }
 
-// NewDepartmentsModel creates a new departments table model
-func NewDepartmentsModel() (*DepartmentsModel, error) {
-	// Load departments from services
-	departments, err := services.GetDepartments()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load departments: %w", err)
-	}
-
-	// Define table columns
-	columns := []table.Column{
-		{Title: "Name", Width: 30},
-		{Title: "Phone", Width: 20},
-		{Title: "Manager", Width: 20},
-		{Title: "Parent Dept", Width: 20},
+// NewDepartmentsModel creates a new departments table model showing phone
+// numbers in phoneStyle. When departments cannot be loaded it still returns an
+// empty, usable model along with the error.
+func NewDepartmentsModel(dir services.Directory, phoneStyle services.PhoneStyle) (*DepartmentsModel, error) {
+	// Load departments from the directory
+	departments, loadErr := dir.GetDepartments()
+	if loadErr != nil {
+		departments = []services.Department{}
+		loadErr = fmt.Errorf("failed to load departments: %w", loadErr)
 	}
+	services.SortDepartments(departments)
 
 	// Build table rows
-	rows := make([]table.Row, 0, len(departments))
-	for _, dept := range departments {
-		manager, err := services.GetPersonById(dept.ManagerId)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get manager: %w", err)
-		}
-		parentDept := ""
-		if dept.ParentDepartmentId != nil {
-			parentDeptObj, err := services.GetDepartmentById(*dept.ParentDepartmentId)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get parent department: %w", err)
-			}
-
-			parentDept = parentDeptObj.Name
-		}
-		rows = append(rows, table.Row{
-			dept.Name,
-			dept.Phone,
-			fmt.Sprintf("%s %s", manager.FirstName, manager.LastName),
-			parentDept,
-		})
-	}
+	rows := departmentRows(dir, departments, phoneStyle)
 
 	// Create table model
 	t := table.New(
-		table.WithColumns(columns),
+		table.WithColumns(departmentColumns),
 		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(20),
@@ -77,9 +62,187 @@ func NewDepartmentsModel() (*DepartmentsModel, error) {
 	t.SetStyles(s)
 
 	return &DepartmentsModel{
-		table: t,
-		ready: false,
-	}, nil
+		dir:         dir,
+		phoneStyle:  phoneStyle,
+		table:       t,
+		all:         departments,
+		departments: departments,
+		expanded:    make(map[string]bool),
+		ready:       false,
+	}, loadErr
+}
+
+// departmentColumns defines the departments table columns in list mode
+var departmentColumns = []table.Column{
+	{Title: "Name", Width: 30},
+	{Title: "Phone", Width: 20},
+	{Title: "Manager", Width: 20},
+	{Title: "Parent Dept", Width: 20},
+}
+
+// departmentRows converts departments to table rows, resolving names through
+// dir and showing phone numbers in phoneStyle
+func departmentRows(dir services.Directory, departments []services.Department, phoneStyle services.PhoneStyle) []table.Row {
+	rows := make([]table.Row, 0, len(departments))
+	for _, dept := range departments {
+		// Unresolved references are shown as placeholders; validation reports them as warnings
+		manager := missingReference(dept.ManagerId)
+		if person, err := dir.GetPersonById(dept.ManagerId); err == nil {
+			manager = fmt.Sprintf("%s %s", person.FirstName, person.LastName)
+		}
+		parentDept := ""
+		if dept.ParentDepartmentId != nil {
+			parentDept = missingReference(*dept.ParentDepartmentId)
+			if parentDeptObj, err := dir.GetDepartmentById(*dept.ParentDepartmentId); err == nil {
+				parentDept = parentDeptObj.Name
+			}
+		}
+		rows = append(rows, table.Row{
+			dept.Name,
+			dept.FormattedPhone(phoneStyle),
+			manager,
+			parentDept,
+		})
+	}
+	return rows
+}
+
+// SetDepartments replaces the listed departments, keeping the selected department and scroll position
+func (m *DepartmentsModel) SetDepartments(dir services.Directory, departments []services.Department) {
+	services.SortDepartments(departments)
+	m.dir = dir
+	m.all = departments
+	if m.detail != nil {
+		m.detail.dir = dir
+		if dept, err := dir.GetDepartmentById(m.detail.dept.Id); err == nil {
+			m.detail.show(*dept)
+		} else {
+			m.detail = nil
+		}
+	}
+	m.refresh(m.selectedId())
+}
+
+// selectedId returns the Id of the department under the cursor
+func (m *DepartmentsModel) selectedId() string {
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.departments) {
+		return m.departments[cursor].Id
+	}
+	return ""
+}
+
+// refresh rebuilds the rows for the current mode and moves the cursor to selectedId when listed
+func (m *DepartmentsModel) refresh(selectedId string) {
+	var rows []table.Row
+	if m.treeMode {
+		m.tree = departmentTree(m.dir, m.all, m.expanded)
+		m.departments = make([]services.Department, len(m.tree))
+		for i, node := range m.tree {
+			m.departments[i] = node.dept
+		}
+		rows = treeRows(m.dir, m.tree, m.expanded, m.phoneStyle)
+	} else {
+		m.tree = nil
+		m.departments = m.all
+		rows = departmentRows(m.dir, m.all, m.phoneStyle)
+	}
+	m.table.SetRows(rows)
+
+	cursor := max(min(m.table.Cursor(), len(m.departments)-1), 0)
+	for i := range m.departments {
+		if m.departments[i].Id == selectedId {
+			cursor = i
+			break
+		}
+	}
+	m.table.SetCursor(cursor)
+}
+
+// toggleTree switches between the flat list and the tree
+func (m *DepartmentsModel) toggleTree() {
+	selectedId := m.selectedId()
+	m.treeMode = !m.treeMode
+
+	// Rows are cleared first so they never have more cells than the columns
+	m.table.SetRows(nil)
+	if m.treeMode {
+		m.table.SetColumns(treeColumns)
+		// Open the path to the selected department so it stays visible
+		ancestors, _ := services.Ancestors(m.dir, selectedId)
+		for _, dept := range ancestors {
+			m.expanded[dept.Id] = true
+		}
+	} else {
+		m.table.SetColumns(departmentColumns)
+	}
+	m.refresh(selectedId)
+}
+
+// treeKey handles the tree navigation keys, reporting whether the key was used
+func (m *DepartmentsModel) treeKey(key string) bool {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.tree) {
+		return false
+	}
+	node := m.tree[cursor]
+
+	switch key {
+	case "right", "l":
+		if node.hasChildren && !m.expanded[node.dept.Id] {
+			m.expanded[node.dept.Id] = true
+			m.refresh(node.dept.Id)
+		} else if node.hasChildren {
+			// Already open: step into the first child
+			m.table.SetCursor(cursor + 1)
+		}
+	case "left", "h":
+		if node.hasChildren && m.expanded[node.dept.Id] {
+			delete(m.expanded, node.dept.Id)
+			m.refresh(node.dept.Id)
+		} else if node.parentId != "" {
+			m.refresh(node.parentId)
+		}
+	case "e":
+		for _, dept := range m.all {
+			m.expanded[dept.Id] = true
+		}
+		m.refresh(node.dept.Id)
+	case "c":
+		// The selection moves to the root it was under
+		root := node
+		for i := cursor; i >= 0 && root.parentId != ""; i-- {
+			if m.tree[i].dept.Id == root.parentId {
+				root = m.tree[i]
+			}
+		}
+		clear(m.expanded)
+		m.refresh(root.dept.Id)
+	default:
+		return false
+	}
+	return true
+}
+
+// missingReference renders a placeholder for an Id that does not resolve. An
+// empty Id is an unset reference and is shown blank.
+func missingReference(id string) string {
+	if id == "" {
+		return ""
+	}
+	if len(id) > 8 {
+		id = id[:8] + "…"
+	}
+	return "⚠ missing " + id
+}
+
+// InputActive reports whether a text input is capturing key presses
+func (m *DepartmentsModel) InputActive() bool {
+	return false
+}
+
+// InSubView reports whether esc should be handled by the model instead of quitting
+func (m *DepartmentsModel) InSubView() bool {
+	return m.detail != nil
 }
 
 // Init initializes the model
@@ -95,6 +258,11 @@ func (m *DepartmentsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.ready {
 			m.ready = true
 		}
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.detail != nil {
+			m.detail.Update(msg)
+		}
 		// Adjust table size based on window size (account for tab bar ~3 lines)
 		availableHeight := msg.Height - 8 // Leave space for borders, padding, and tab bar
 		availableWidth := msg.Width - 8
@@ -106,10 +274,28 @@ func (m *DepartmentsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case closeDetailMsg:
+		m.detail = nil
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.detail != nil {
+			_, cmd = m.detail.Update(msg)
+			return m, cmd
+		}
+
+		if m.treeMode && m.treeKey(msg.String()) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "enter":
-			// Handle row selection if needed
+			if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.departments) {
+				m.detail = NewDepartmentDetailModel(m.dir, m.departments[cursor], m.phoneStyle, m.width, m.height)
+			}
+			return m, nil
+		case "t":
+			m.toggleTree()
 			return m, nil
 		}
 	}
@@ -123,6 +309,9 @@ func (m *DepartmentsModel) View() string {
 	if !m.ready {
 		return "Loading departments..."
 	}
+	if m.detail != nil {
+		return m.detail.View()
+	}
 
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+func TestDepartmentsWithUnsetReferences(t *testing.T) {
+	empty := ""
+	store := services.NewStore(nil, []services.Department{
+		{Id: "root", Name: "Root"},
+		{Id: "blank", Name: "Blank", ParentDepartmentId: &empty},
+		{Id: "gone", Name: "Gone", ManagerId: "missing-person"},
+	})
+	model, err := NewDepartmentsModel(store, services.PhoneAsEntered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Columns are name, phone, manager and parent
+	managers := make(map[string]string)
+	for _, row := range departmentRows(store, model.all, services.PhoneAsEntered) {
+		managers[row[0]] = row[2]
+		if row[3] != "" {
+			t.Errorf("%s has parent %q, want blank", row[0], row[3])
+		}
+	}
+	if managers["Root"] != "" || managers["Blank"] != "" {
+		t.Errorf("departments without a manager show %q and %q, want blank", managers["Root"], managers["Blank"])
+	}
+	if managers["Gone"] != "⚠ missing missing-…" {
+		t.Errorf("dangling manager shows %q", managers["Gone"])
+	}
+
+	var roots []string
+	for _, node := range departmentTree(store, model.all, nil) {
+		roots = append(roots, node.dept.Id)
+	}
+	if len(roots) != 3 {
+		t.Errorf("tree roots are %v, want every department", roots)
+	}
+}
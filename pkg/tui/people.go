@@ -3,62 +3,57 @@ package tui
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/htekgulds/terminal-rehber/services"
 )
 
+// peopleColumns defines the people table columns
+var peopleColumns = []table.Column{
+	{Title: "Name", Width: 35},
+	{Title: "Title", Width: 25},
+	{Title: "Room", Width: 10},
+	{Title: "Contact", Width: 22},
+	{Title: "Floor", Width: 6},
+}
+
 // PeopleModel represents the people table model
 type PeopleModel struct {
-	table  table.Model
-	people []services.Person
-	width  int
-	height int
-	ready  bool
+	dir        services.Directory
+	phoneStyle services.PhoneStyle
+	table      table.Model
+	all        []services.Person
+	people     []services.Person // people matching the search, in table order
+	search     textinput.Model
+	searching  bool
+	detail     *PersonDetailModel
+	width      int
+	height     int
+	ready      bool
 }
 
-// NewPeopleModel creates a new people table model
-func NewPeopleModel() *PeopleModel {
+// NewPeopleModel creates a new people table model showing phone numbers in
+// phoneStyle. When people cannot be loaded it still returns an empty, usable
+// model along with the error.
+func NewPeopleModel(dir services.Directory, phoneStyle services.PhoneStyle) (*PeopleModel, error) {
 	// Fetch people data
-	people, err := services.GetPeople()
-	if err != nil {
-		// If there's an error, create empty model
-		// In a real scenario, you might want to handle this differently
+	people, loadErr := dir.GetPeople()
+	if loadErr != nil {
 		people = []services.Person{}
+		loadErr = fmt.Errorf("failed to load people: %w", loadErr)
 	}
-
-	// Define table columns
-	columns := []table.Column{
-		{Title: "Name", Width: 35},
-		{Title: "Title", Width: 25},
-		{Title: "Room", Width: 10},
-		{Title: "Phone", Width: 18},
-		{Title: "Floor", Width: 6},
-	}
+	services.SortPeople(people)
 
 	// Convert people to table rows
-	rows := make([]table.Row, len(people))
-	for i, person := range people {
-		fullName := fmt.Sprintf("%s %s", person.FirstName, person.LastName)
-		// Combine prefix and name
-		nameWithPrefix := fullName
-		if person.Prefix != nil && *person.Prefix != "" {
-			nameWithPrefix = fmt.Sprintf("%s %s", *person.Prefix, fullName)
-		}
-		rows[i] = table.Row{
-			nameWithPrefix,
-			person.Title,
-			person.Room,
-			person.Phone,
-			strconv.Itoa(person.Floor),
-		}
-	}
+	rows := peopleRows(people, nil, phoneStyle)
 
 	// Create table
 	t := table.New(
-		table.WithColumns(columns),
+		table.WithColumns(peopleColumns),
 		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(20),
@@ -77,10 +72,136 @@ func NewPeopleModel() *PeopleModel {
 		Bold(false)
 	t.SetStyles(s)
 
+	// Search input, opened with "/"
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "name, title, room or phone"
+	search.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+
 	return &PeopleModel{
-		table:  t,
-		people: people,
+		dir:        dir,
+		phoneStyle: phoneStyle,
+		table:      t,
+		all:        people,
+		people:     people,
+		search:     search,
+	}, loadErr
+}
+
+// peopleFields names the search fields shown in each column
+var peopleFields = []string{"name", "title", "room", "phone", ""}
+
+// peopleRows converts people to table rows with phone numbers in phoneStyle,
+// highlighting the search matches
+func peopleRows(people []services.Person, results []services.SearchResult, phoneStyle services.PhoneStyle) []table.Row {
+	rows := make([]table.Row, len(people))
+	for i, person := range people {
+		row := table.Row{
+			person.DisplayName(),
+			person.Title,
+			person.Room,
+			person.FormattedPhone(phoneStyle),
+			strconv.Itoa(person.Floor),
+		}
+
+		// The primary channel is shown with a count of the others
+		var more string
+		if contacts := person.ContactPoints(); len(contacts) > 0 {
+			if primary, ok := person.PrimaryContact(); ok {
+				row[3] = primary.FormattedValue(phoneStyle)
+			}
+			if len(contacts) > 1 {
+				more = fmt.Sprintf(" +%d", len(contacts)-1)
+			}
+		}
+
+		if results != nil {
+			for c := range row {
+				// Spans point into the phone number as entered, not a reformatted one
+				if peopleFields[c] == "phone" && row[c] != person.Phone {
+					continue
+				}
+				width := peopleColumns[c].Width
+				if c == 3 {
+					width -= len(more)
+				}
+				row[c] = highlight(row[c], results[i].Spans(peopleFields[c]), width)
+			}
+		}
+		row[3] += more
+		rows[i] = row
+	}
+	return rows
+}
+
+// SetPeople replaces the listed people, keeping the search, selected person and scroll position
+func (m *PeopleModel) SetPeople(dir services.Directory, people []services.Person) {
+	services.SortPeople(people)
+	m.dir = dir
+	m.all = people
+	if m.detail != nil {
+		m.detail.dir = dir
+		if person, err := dir.GetPersonById(m.detail.person.Id); err == nil {
+			m.detail.show(*person)
+		} else {
+			m.detail = nil
+		}
+	}
+	m.applySearch()
+}
+
+// applySearch filters the table by the search input, keeping the selected person when still listed
+func (m *PeopleModel) applySearch() {
+	selectedId := ""
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.people) {
+		selectedId = m.people[cursor].Id
+	}
+
+	m.people = m.all
+	var results []services.SearchResult
+	if strings.TrimSpace(m.search.Value()) != "" {
+		// Matching people are listed best match first
+		results = services.SearchPeople(m.all, m.search.Value(), services.SearchOptions{})
+		m.people = make([]services.Person, len(results))
+		for i, result := range results {
+			m.people[i] = *result.Person
+		}
+	}
+	m.table.SetRows(peopleRows(m.people, results, m.phoneStyle))
+
+	cursor := max(min(m.table.Cursor(), len(m.people)-1), 0)
+	for i := range m.people {
+		if m.people[i].Id == selectedId {
+			cursor = i
+			break
+		}
+	}
+	m.table.SetCursor(cursor)
+}
+
+// InputActive reports whether the search input is capturing key presses
+func (m *PeopleModel) InputActive() bool {
+	return m.searching
+}
+
+// HasSearch reports whether the search input is open or a filter is applied
+func (m *PeopleModel) HasSearch() bool {
+	return m.searching || m.search.Value() != ""
+}
+
+// InSubView reports whether esc should be handled by the model instead of quitting
+func (m *PeopleModel) InSubView() bool {
+	return m.detail != nil || m.HasSearch()
+}
+
+// resize fits the table into the window, leaving room for the search bar when shown
+func (m *PeopleModel) resize() {
+	height := m.height - 8
+	if m.HasSearch() {
+		height -= 2
 	}
+	m.table.SetWidth(m.width - 8)
+	m.table.SetHeight(height)
 }
 
 // Init initializes the model
@@ -97,15 +218,62 @@ func (m *PeopleModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
-		// Update table width to fit window (account for tab bar ~3 lines)
-		m.table.SetWidth(msg.Width - 8)
-		m.table.SetHeight(msg.Height - 8)
+		// Update table size to fit window (account for tab bar ~3 lines)
+		m.resize()
+		if m.detail != nil {
+			m.detail.Update(msg)
+		}
+		return m, nil
+
+	case closeDetailMsg:
+		m.detail = nil
 		return m, nil
+	}
 
+	if m.detail != nil {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			_, cmd = m.detail.Update(msg)
+			return m, cmd
+		}
+	}
+
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.searching {
+			switch msg.String() {
+			case "esc":
+				m.closeSearch()
+				return m, nil
+			case "enter", "up", "down":
+				// Leave the input and continue in the table with the filter applied
+				m.searching = false
+				m.search.Blur()
+				m.table.Focus()
+				if msg.String() == "enter" {
+					return m, nil
+				}
+			default:
+				m.search, cmd = m.search.Update(msg)
+				m.applySearch()
+				return m, cmd
+			}
+		}
+
 		switch msg.String() {
+		case "/":
+			m.searching = true
+			m.table.Blur()
+			m.resize()
+			return m, m.search.Focus()
+		case "esc":
+			if m.search.Value() != "" {
+				m.closeSearch()
+			}
+			return m, nil
 		case "enter":
-			// Handle row selection if needed
+			if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.people) {
+				m.detail = NewPersonDetailModel(m.dir, m.people[cursor], m.phoneStyle, m.width, m.height)
+			}
 			return m, nil
 		}
 	}
@@ -115,23 +283,38 @@ func (m *PeopleModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// closeSearch clears the filter and hides the search bar
+func (m *PeopleModel) closeSearch() {
+	m.searching = false
+	m.search.Blur()
+	m.search.SetValue("")
+	m.table.Focus()
+	m.applySearch()
+	m.resize()
+}
+
 // View renders the UI
 func (m *PeopleModel) View() string {
 	if !m.ready {
 		return "Loading people data..."
 	}
+	if m.detail != nil {
+		return m.detail.View()
+	}
 
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("62")).
 		Padding(1, 2)
 
-	// help := lipgloss.NewStyle().
-	// 	Foreground(lipgloss.Color("240")).
-	// 	MarginTop(1).
-	// 	Render("↑/↓: Navigate • Enter: Select")
-
-	content := fmt.Sprintf("%s", m.table.View())
+	content := m.table.View()
+	if m.HasSearch() {
+		counter := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Render(fmt.Sprintf("  %d of %d", len(m.people), len(m.all)))
+		searchBar := lipgloss.JoinHorizontal(lipgloss.Top, m.search.View(), counter)
+		content = lipgloss.JoinVertical(lipgloss.Left, searchBar, "", content)
+	}
 
 	return style.Render(content)
 }
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// closeDetailMsg asks the owning tab to close its detail view
+type closeDetailMsg struct{}
+
+func closeDetail() tea.Msg {
+	return closeDetailMsg{}
+}
+
+// PersonDetailModel shows everything known about one person and lets the
+// user walk through their colleagues
+type PersonDetailModel struct {
+	dir        services.Directory
+	phoneStyle services.PhoneStyle
+	person     services.Person
+	colleagues []services.Person
+	cursor     int
+	history    []string // Ids of the people shown before, for backspace
+	// allContacts lists every contact point instead of only the primary one
+	allContacts bool
+	width       int
+	height      int
+}
+
+// NewPersonDetailModel creates a detail view for person, showing phone numbers in phoneStyle
+func NewPersonDetailModel(dir services.Directory, person services.Person, phoneStyle services.PhoneStyle, width, height int) *PersonDetailModel {
+	m := &PersonDetailModel{
+		dir:        dir,
+		phoneStyle: phoneStyle,
+		width:      width,
+		height:     height,
+	}
+	m.show(person)
+	return m
+}
+
+// show switches the view to person
+func (m *PersonDetailModel) show(person services.Person) {
+	m.person = person
+	m.cursor = 0
+	m.colleagues = nil
+
+	members, _ := m.dir.GetPeopleByDepartmentId(person.DepartmentId)
+	for _, member := range members {
+		if member.Id != person.Id {
+			m.colleagues = append(m.colleagues, member)
+		}
+	}
+	services.SortPeople(m.colleagues)
+}
+
+// Init initializes the model
+func (m *PersonDetailModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model
+func (m *PersonDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.colleagues)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if m.cursor < len(m.colleagues) {
+				m.history = append(m.history, m.person.Id)
+				m.show(m.colleagues[m.cursor])
+			}
+		case "backspace":
+			if len(m.history) == 0 {
+				return m, closeDetail
+			}
+			previous := m.history[len(m.history)-1]
+			m.history = m.history[:len(m.history)-1]
+			if person, err := m.dir.GetPersonById(previous); err == nil {
+				m.show(*person)
+			}
+		case "c":
+			m.allContacts = !m.allContacts
+		case "esc":
+			return m, closeDetail
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the UI
+func (m *PersonDetailModel) View() string {
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Width(14)
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229"))
+	section := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62")).MarginTop(1)
+
+	field := func(name, value string) string {
+		if value == "" {
+			value = "—"
+		}
+		return label.Render(name) + value
+	}
+
+	person := m.person
+	lines := []string{
+		title.Render(person.DisplayName()),
+		"",
+		field("Title", person.Title),
+		field("Room", person.Room),
+		field("Floor", strconv.Itoa(person.Floor)),
+	}
+
+	contacts := person.ContactPoints()
+	for _, contact := range contacts {
+		if !contact.Primary && !m.allContacts {
+			continue
+		}
+		value := contact.FormattedValue(m.phoneStyle)
+		if contact.Primary && len(contacts) > 1 {
+			if m.allContacts {
+				value += label.UnsetWidth().Render(" (primary)")
+			} else {
+				value += label.UnsetWidth().Render(fmt.Sprintf(" (+%d more, c: show all)", len(contacts)-1))
+			}
+		}
+		lines = append(lines, field(capitalize(contact.Name()), value))
+	}
+	if len(contacts) == 0 {
+		lines = append(lines, field("Phone", ""))
+	}
+
+	dept, err := m.dir.GetDepartmentById(person.DepartmentId)
+	if err != nil {
+		lines = append(lines, field("Department", missingReference(person.DepartmentId)))
+	} else {
+		lines = append(lines,
+			field("Department", dept.Name),
+			field("Dept. phone", dept.FormattedPhone(m.phoneStyle)),
+			field("Hierarchy", strings.Join(departmentPath(m.dir, *dept), " › ")),
+		)
+	}
+
+	manager := "—"
+	if mgr, _ := services.Manager(m.dir, person.Id); mgr != nil {
+		manager = fmt.Sprintf("%s (%s)", mgr.DisplayName(), mgr.FormattedPhone(m.phoneStyle))
+	}
+	lines = append(lines, field("Manager", manager))
+
+	lines = append(lines, section.Render(fmt.Sprintf("Colleagues (%d)", len(m.colleagues))))
+	if len(m.colleagues) == 0 {
+		lines = append(lines, label.Render("none"))
+	}
+
+	// Show a window of colleagues around the cursor that fits the screen
+	visible := max(m.height-len(lines)-14, 3)
+	start := max(0, min(m.cursor-visible/2, len(m.colleagues)-visible))
+	end := min(len(m.colleagues), start+visible)
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	for i := start; i < end; i++ {
+		colleague := m.colleagues[i]
+		line := fmt.Sprintf(" %-35s %-25s %s ", colleague.DisplayName(), colleague.Title, colleague.FormattedPhone(m.phoneStyle))
+		if i == m.cursor {
+			line = selected.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	hint := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Render("↑/↓: Colleagues • Enter: Open • c: Contacts • Backspace: Back • Esc: Close")
+	lines = append(lines, hint)
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(m.width - 4)
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// departmentPath returns the names from the top-level department down to dept
+func departmentPath(dir services.Directory, dept services.Department) []string {
+	ancestors, err := services.Ancestors(dir, dept.Id)
+	top := dept
+	if len(ancestors) > 0 {
+		top = ancestors[len(ancestors)-1]
+	}
+
+	var path []string
+	// A parent left after a complete walk does not exist
+	if err == nil && top.HasParent() {
+		path = append(path, missingReference(*top.ParentDepartmentId))
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		path = append(path, ancestors[i].Name)
+	}
+	return append(path, dept.Name)
+}
+
+// capitalize upper-cases the first letter of a field name
+func capitalize(s string) string {
+	for i := range s {
+		if i > 0 {
+			return strings.ToUpper(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToUpper(s)
+}
@@ -1,8 +1,13 @@
 package tui
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/htekgulds/terminal-rehber/services"
 )
 
 const (
@@ -12,29 +17,85 @@ const (
 
 // Model represents the TUI model with tabs
 type Model struct {
-	width       int
-	height      int
-	ready       bool
-	activeTab   int
-	peopleModel *PeopleModel
-	deptModel   *DepartmentsModel
-	tabNames    []string
+	dir          services.Directory
+	phoneStyle   services.PhoneStyle
+	width        int
+	height       int
+	ready        bool
+	activeTab    int
+	peopleModel  *PeopleModel
+	deptModel    *DepartmentsModel
+	tabNames     []string
+	warnings     []string
+	showWarnings bool
+}
+
+// DataReloadedMsg tells the TUI that the directory data changed on disk
+type DataReloadedMsg struct {
+	// Directory is the reloaded data; nil keeps the current data
+	Directory services.Directory
+	// LoadErrors lists the problems encountered while reloading
+	LoadErrors []error
 }
 
-// NewModel creates a new TUI model with tabs
-func NewModel() (*Model, error) {
-	peopleModel := NewPeopleModel()
-	deptModel, err := NewDepartmentsModel()
+// NewModel creates a new TUI model with tabs backed by the given directory,
+// showing phone numbers in phoneStyle. Load errors and data problems do not
+// prevent startup; they are listed in a warnings panel instead.
+func NewModel(dir services.Directory, loadErrors []error, phoneStyle services.PhoneStyle) *Model {
+	var warnings []string
+	peopleModel, err := NewPeopleModel(dir, phoneStyle)
 	if err != nil {
-		return nil, err
+		warnings = append(warnings, err.Error())
 	}
+	deptModel, err := NewDepartmentsModel(dir, phoneStyle)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	warnings = append(dataWarnings(dir, loadErrors), warnings...)
 
 	return &Model{
-		activeTab:   tabPeople,
-		peopleModel: peopleModel,
-		deptModel:   deptModel,
-		tabNames:    []string{"People", "Departments"},
-	}, nil
+		dir:          dir,
+		phoneStyle:   phoneStyle,
+		activeTab:    tabPeople,
+		peopleModel:  peopleModel,
+		deptModel:    deptModel,
+		tabNames:     []string{"People", "Departments"},
+		warnings:     warnings,
+		showWarnings: len(warnings) > 0,
+	}
+}
+
+// dataWarnings lists load errors followed by the validation problems in dir
+func dataWarnings(dir services.Directory, loadErrors []error) []string {
+	var warnings []string
+	for _, err := range loadErrors {
+		warnings = append(warnings, err.Error())
+	}
+
+	people, _ := dir.GetPeople()
+	departments, _ := dir.GetDepartments()
+	for _, problem := range services.Validate(people, departments) {
+		warnings = append(warnings, problem.String())
+	}
+
+	return warnings
+}
+
+// reload rebuilds the tables from the reloaded data, keeping selections
+func (m *Model) reload(msg DataReloadedMsg) {
+	if msg.Directory != nil {
+		m.dir = msg.Directory
+		people, _ := m.dir.GetPeople()
+		departments, _ := m.dir.GetDepartments()
+		m.peopleModel.SetPeople(m.dir, people)
+		m.deptModel.SetDepartments(m.dir, departments)
+	}
+
+	warnings := dataWarnings(m.dir, msg.LoadErrors)
+	if !slices.Equal(warnings, m.warnings) {
+		m.showWarnings = len(warnings) > 0
+	}
+	m.warnings = warnings
 }
 
 // Init initializes the model
@@ -47,7 +108,6 @@ func (m *Model) Init() tea.Cmd {
 
 // Update handles messages and updates the model
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -67,7 +127,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, tea.Batch(cmds...)
 
+	case DataReloadedMsg:
+		m.reload(msg)
+		return m, nil
+
 	case tea.KeyMsg:
+		// While a text input is open every key except ctrl+c goes to it,
+		// and esc closes sub-views before quitting
+		if view := m.activeView(); !m.showWarnings && msg.String() != "ctrl+c" &&
+			(view.InputActive() || msg.String() == "esc" && view.InSubView()) {
+			return m, m.updateActive(msg)
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -85,25 +156,60 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "2":
 			m.activeTab = tabDepartments
 			return m, nil
+		case "w":
+			if len(m.warnings) > 0 {
+				m.showWarnings = !m.showWarnings
+			}
+			return m, nil
 		case "esc":
-			// ESC quits only if not in a sub-view
+			// ESC closes the warnings panel first, then quits
+			if m.showWarnings {
+				m.showWarnings = false
+				return m, nil
+			}
 			return m, tea.Quit
 		}
+
+		if m.showWarnings {
+			// Keys are not forwarded to the hidden table while the panel is open
+			return m, nil
+		}
 	}
 
 	// Forward update to active model
+	return m, m.updateActive(msg)
+}
+
+// tabView is implemented by the tab models
+type tabView interface {
+	tea.Model
+	// InputActive reports whether a text input is capturing key presses
+	InputActive() bool
+	// InSubView reports whether esc should be handled by the tab instead of quitting
+	InSubView() bool
+}
+
+// activeView returns the model of the active tab
+func (m *Model) activeView() tabView {
+	if m.activeTab == tabDepartments {
+		return m.deptModel
+	}
+	return m.peopleModel
+}
+
+// updateActive forwards msg to the active tab
+func (m *Model) updateActive(msg tea.Msg) tea.Cmd {
 	switch m.activeTab {
 	case tabPeople:
 		peopleModel, cmd := m.peopleModel.Update(msg)
 		m.peopleModel = peopleModel.(*PeopleModel)
-		return m, cmd
+		return cmd
 	case tabDepartments:
 		deptModel, cmd := m.deptModel.Update(msg)
 		m.deptModel = deptModel.(*DepartmentsModel)
-		return m, cmd
+		return cmd
 	}
-
-	return m, cmd
+	return nil
 }
 
 // View renders the UI
@@ -117,18 +223,37 @@ func (m *Model) View() string {
 
 	// Render active view
 	var content string
-	switch m.activeTab {
-	case tabPeople:
+	switch {
+	case m.showWarnings:
+		content = m.renderWarnings()
+	case m.activeTab == tabPeople:
 		content = m.peopleModel.View()
-	case tabDepartments:
+	case m.activeTab == tabDepartments:
 		content = m.deptModel.View()
 	}
 
+	help := "Tab/Shift+Tab: Switch • 1/2: Jump • ↑/↓: Navigate • Enter: Select • q: Quit"
+	if m.activeTab == tabDepartments && m.deptModel.detail == nil {
+		if m.deptModel.treeMode {
+			help += " • ←/→: Collapse/Expand • e/c: Expand/Collapse all • t: List"
+		} else {
+			help += " • t: Tree"
+		}
+	}
+	if m.activeTab == tabPeople && m.peopleModel.detail == nil {
+		help += " • /: Search"
+		if m.peopleModel.HasSearch() {
+			help += " • Esc: Clear search"
+		}
+	}
+	if len(m.warnings) > 0 {
+		help += fmt.Sprintf(" • w: Warnings (%d)", len(m.warnings))
+	}
 	helpText := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240")).
 		MarginLeft(2).
 		MarginTop(1).
-		Render("Tab/Shift+Tab: Switch • 1/2: Jump • ↑/↓: Navigate • Enter: Select • q: Quit")
+		Render(help)
 
 	// Combine tabs and content
 	return lipgloss.JoinVertical(lipgloss.Left, tabs, content, helpText)
@@ -176,3 +301,34 @@ func (m *Model) renderTabs() string {
 		PaddingLeft(2).
 		Render(tabBar)
 }
+
+// renderWarnings renders the panel listing data load problems
+func (m *Model) renderWarnings() string {
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true).
+		Render(fmt.Sprintf("⚠ %d data problems", len(m.warnings)))
+
+	// Keep the panel within the same height as the tables
+	maxLines := m.height - 12
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	lines := m.warnings
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines:maxLines], fmt.Sprintf("… and %d more, run `rehber validate` for the full list", len(m.warnings)-maxLines))
+	}
+
+	hint := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("esc/w: Dismiss")
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1, 2).
+		Width(m.width - 4)
+
+	body := lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(lines, "\n"), "", hint)
+	return style.Render(body)
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/services/servicestest"
+)
+
+// benchmarkSize is the number of people in the generated benchmark directory
+const benchmarkSize = 100_000
+
+// startupBudget is how long startup may take with benchmarkSize people
+const startupBudget = time.Second
+
+// startup does what the root command does before showing the TUI
+func startup(dir services.Directory) (*Model, error) {
+	store, err := services.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewModel(store, nil, services.PhoneAsEntered), nil
+}
+
+func BenchmarkNewModel(b *testing.B) {
+	store := services.NewStore(servicestest.Generate(benchmarkSize))
+
+	for b.Loop() {
+		NewModel(store, nil, services.PhoneAsEntered)
+	}
+}
+
+func BenchmarkStartup(b *testing.B) {
+	dir, err := servicestest.WriteJSON(b.TempDir(), benchmarkSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		if _, err := startup(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestStartupTime checks startup against startupBudget. Wall-clock budgets
+// fail on slow machines, so it only runs when REHBER_STARTUP_BUDGET_TEST is
+// set; BenchmarkStartup measures the same work.
+func TestStartupTime(t *testing.T) {
+	if os.Getenv("REHBER_STARTUP_BUDGET_TEST") == "" {
+		t.Skip("set REHBER_STARTUP_BUDGET_TEST=1 to check the startup budget")
+	}
+	dir, err := servicestest.WriteJSON(t.TempDir(), benchmarkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fastest of a few runs is timed, so a busy machine does not fail the test
+	var model *Model
+	elapsed := time.Duration(math.MaxInt64)
+	for range 3 {
+		start := time.Now()
+		model, err = startup(dir)
+		elapsed = min(elapsed, time.Since(start))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed <= startupBudget {
+			break
+		}
+	}
+	if len(model.warnings) > 0 {
+		t.Errorf("generated directory has warnings: %v", model.warnings[:min(3, len(model.warnings))])
+	}
+	if elapsed > startupBudget {
+		t.Errorf("startup with %d people took %v, want under %v", benchmarkSize, elapsed, startupBudget)
+	}
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/mattn/go-runewidth"
+)
+
+// Highlight markers only toggle bold and underline so the selected row keeps its colors
+const (
+	highlightStart = "\x1b[1;4m"
+	highlightEnd   = "\x1b[22;24m"
+)
+
+// highlight marks the matched spans of value. The markers are skipped when
+// they would push the cell past width, since the table truncates cells
+// without accounting for escape sequences.
+func highlight(value string, spans []services.Span, width int) string {
+	if len(spans) == 0 {
+		return value
+	}
+
+	runes := []rune(value)
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span.Start < last || span.End > len(runes) {
+			return value
+		}
+		b.WriteString(string(runes[last:span.Start]))
+		b.WriteString(highlightStart)
+		b.WriteString(string(runes[span.Start:span.End]))
+		b.WriteString(highlightEnd)
+		last = span.End
+	}
+	b.WriteString(string(runes[last:]))
+
+	if runewidth.StringWidth(b.String()) > width {
+		return value
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// treeColumns defines the departments table columns in tree mode
+var treeColumns = []table.Column{
+	{Title: "Name", Width: 45},
+	{Title: "People", Width: 8},
+	{Title: "Phone", Width: 20},
+	{Title: "Manager", Width: 20},
+}
+
+// treeNode is a visible row of the department tree
+type treeNode struct {
+	dept        services.Department
+	parentId    string
+	guide       string // indentation guides drawn before the name
+	hasChildren bool
+	headcount   int // members of the department and all its descendants
+}
+
+// departmentTree flattens the department hierarchy into the rows visible with
+// the given expanded departments. Departments whose parent is missing, and the
+// first department of each parent cycle, are shown as roots.
+func departmentTree(dir services.Directory, departments []services.Department, expanded map[string]bool) []treeNode {
+	ids := make(map[string]bool, len(departments))
+	children := make(map[string][]services.Department)
+	for _, dept := range departments {
+		ids[dept.Id] = true
+	}
+	var roots []services.Department
+	for _, dept := range departments {
+		if dept.ParentDepartmentId == nil || !ids[*dept.ParentDepartmentId] {
+			roots = append(roots, dept)
+		} else {
+			children[*dept.ParentDepartmentId] = append(children[*dept.ParentDepartmentId], dept)
+		}
+	}
+
+	// Departments only reachable through a parent cycle become roots too
+	reached := make(map[string]bool)
+	var reach func(id string)
+	reach = func(id string) {
+		if reached[id] {
+			return
+		}
+		reached[id] = true
+		for _, child := range children[id] {
+			reach(child.Id)
+		}
+	}
+	for _, dept := range roots {
+		reach(dept.Id)
+	}
+	for _, dept := range departments {
+		if !reached[dept.Id] {
+			roots = append(roots, dept)
+			reach(dept.Id)
+		}
+	}
+
+	members := make(map[string]int)
+	people, _ := dir.GetPeople()
+	for _, person := range people {
+		members[person.DepartmentId]++
+	}
+
+	// Headcounts include descendants; visiting guards against parent cycles
+	headcounts := make(map[string]int)
+	visiting := make(map[string]bool)
+	var headcount func(id string) int
+	headcount = func(id string) int {
+		if n, ok := headcounts[id]; ok {
+			return n
+		}
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		n := members[id]
+		for _, child := range children[id] {
+			n += headcount(child.Id)
+		}
+		headcounts[id] = n
+		return n
+	}
+
+	var nodes []treeNode
+	placed := make(map[string]bool)
+	var walk func(dept services.Department, parentId, indent string, last, root bool)
+	walk = func(dept services.Department, parentId, indent string, last, root bool) {
+		if placed[dept.Id] {
+			return
+		}
+		placed[dept.Id] = true
+
+		guide, childIndent := "", ""
+		if !root {
+			guide, childIndent = indent+"├─ ", indent+"│  "
+			if last {
+				guide, childIndent = indent+"└─ ", indent+"   "
+			}
+		}
+		nodes = append(nodes, treeNode{
+			dept:        dept,
+			parentId:    parentId,
+			guide:       guide,
+			hasChildren: len(children[dept.Id]) > 0,
+			headcount:   headcount(dept.Id),
+		})
+
+		if expanded[dept.Id] {
+			kids := children[dept.Id]
+			for i, child := range kids {
+				walk(child, dept.Id, childIndent, i == len(kids)-1, false)
+			}
+		}
+	}
+
+	for _, dept := range roots {
+		walk(dept, "", "", false, true)
+	}
+
+	return nodes
+}
+
+// treeRows converts tree nodes to table rows with phone numbers in phoneStyle
+func treeRows(dir services.Directory, nodes []treeNode, expanded map[string]bool, phoneStyle services.PhoneStyle) []table.Row {
+	rows := make([]table.Row, len(nodes))
+	for i, node := range nodes {
+		marker := "  "
+		if node.hasChildren {
+			marker = "▸ "
+			if expanded[node.dept.Id] {
+				marker = "▾ "
+			}
+		}
+
+		manager := missingReference(node.dept.ManagerId)
+		if person, err := dir.GetPersonById(node.dept.ManagerId); err == nil {
+			manager = person.FullName()
+		}
+
+		rows[i] = table.Row{
+			node.guide + marker + node.dept.Name,
+			strconv.Itoa(node.headcount),
+			node.dept.FormattedPhone(phoneStyle),
+			manager,
+		}
+	}
+	return rows
+}
@@ -0,0 +1,261 @@
+package vcard
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// Extension properties keep the fields vCard has no place for, so exported
+// cards import back unchanged
+const (
+	propRoom         = "X-REHBER-ROOM"
+	propFloor        = "X-REHBER-FLOOR"
+	propDepartmentId = "X-REHBER-DEPARTMENT-ID"
+	paramLabel       = "X-REHBER-LABEL"
+)
+
+// uidPrefix is put before person Ids to form URN UIDs
+const uidPrefix = "urn:uuid:"
+
+// FromPerson builds the card of a person. ORG lists the department hierarchy
+// from the top-level department down, and the room and floor are written as
+// the extended part of a work address.
+func FromPerson(dir services.Directory, person services.Person) Card {
+	var card Card
+	card.Add("KIND", "individual", nil)
+	card.Add("UID", uidPrefix+person.Id, nil)
+	card.Add("FN", person.DisplayName(), nil)
+	prefix := ""
+	if person.Prefix != nil {
+		prefix = *person.Prefix
+	}
+	card.AddStructured("N", []string{person.LastName, person.FirstName, "", prefix, ""}, nil)
+	if person.Title != "" {
+		card.Add("TITLE", person.Title, nil)
+	}
+
+	if dept, err := dir.GetDepartmentById(person.DepartmentId); err == nil {
+		ancestors, _ := services.Ancestors(dir, dept.Id)
+		org := make([]string, 0, len(ancestors)+1)
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			org = append(org, ancestors[i].Name)
+		}
+		card.AddStructured("ORG", append(org, dept.Name), nil)
+	}
+
+	for _, contact := range person.ContactPoints() {
+		params := map[string][]string{}
+		if contact.Primary {
+			params["PREF"] = []string{"1"}
+		}
+		if contact.Label != "" {
+			params[paramLabel] = []string{contact.Label}
+		}
+
+		switch contact.Kind {
+		case services.ContactEmail:
+			params["TYPE"] = []string{"work"}
+			card.Add("EMAIL", contact.Value, params)
+		default:
+			params["TYPE"] = telTypes(contact.Kind)
+			if phone, err := services.ParsePhone(contact.Value); err == nil && phone.E164() != "" {
+				// Parsed numbers are written as tel: URIs, the vCard 4.0 form
+				uri := "tel:" + phone.E164()
+				if phone.Extension != "" {
+					uri += ";ext=" + phone.Extension
+				}
+				params["VALUE"] = []string{"uri"}
+				card.Properties = append(card.Properties, Property{Name: "TEL", Params: params, Value: uri})
+			} else {
+				params["VALUE"] = []string{"text"}
+				card.Add("TEL", contact.Value, params)
+			}
+		}
+	}
+
+	if person.Room != "" || person.Floor != 0 {
+		card.AddStructured("ADR", []string{"", roomAndFloor(person), "", "", "", "", ""}, map[string][]string{"TYPE": {"work"}})
+		card.Add(propRoom, person.Room, nil)
+		card.Add(propFloor, strconv.Itoa(person.Floor), nil)
+	}
+	card.Add(propDepartmentId, person.DepartmentId, nil)
+
+	return card
+}
+
+// telTypes returns the TEL TYPE values of a phone contact kind
+func telTypes(kind services.ContactKind) []string {
+	switch kind {
+	case services.ContactMobile:
+		return []string{"cell", "voice"}
+	case services.ContactFax:
+		return []string{"work", "fax"}
+	}
+	return []string{"work", "voice"}
+}
+
+// roomAndFloor renders the extended address, e.g. "Room A-205, Floor 2"
+func roomAndFloor(person services.Person) string {
+	var parts []string
+	if person.Room != "" {
+		parts = append(parts, "Room "+person.Room)
+	}
+	parts = append(parts, "Floor "+strconv.Itoa(person.Floor))
+	return strings.Join(parts, ", ")
+}
+
+// roomAndFloorPattern reads back the extended address written by roomAndFloor
+var roomAndFloorPattern = regexp.MustCompile(`^(?:Room (.+?))?(?:, )?(?:Floor (-?\d+))?$`)
+
+// ToPerson maps a card to a person, resolving the department through dir by
+// X-REHBER-DEPARTMENT-ID or by the last ORG unit. Cards written by other
+// applications have no Id unless their UID is a urn:uuid. Warnings describe
+// what could not be mapped.
+func ToPerson(dir services.Directory, card Card) (services.Person, []string, error) {
+	var person services.Person
+	var warnings []string
+
+	if uid := card.Text("UID"); strings.HasPrefix(strings.ToLower(uid), uidPrefix) {
+		person.Id = uid[len(uidPrefix):]
+	}
+
+	if n, ok := card.Get("N"); ok {
+		components := append(n.Components(), "", "", "", "")
+		person.LastName = strings.TrimSpace(components[0])
+		person.FirstName = strings.TrimSpace(strings.Join(strings.Fields(components[1]+" "+components[2]), " "))
+		if prefix := strings.TrimSpace(components[3]); prefix != "" {
+			person.Prefix = &prefix
+		}
+	} else if fn := strings.Fields(card.Text("FN")); len(fn) > 0 {
+		// Without N the formatted name is split at its last word
+		person.LastName = fn[len(fn)-1]
+		person.FirstName = strings.Join(fn[:len(fn)-1], " ")
+	}
+	if person.FirstName == "" && person.LastName == "" {
+		return person, nil, fmt.Errorf("card has no name")
+	}
+	person.Title = card.Text("TITLE")
+
+	person.Room = card.Text(propRoom)
+	if floor := card.Text(propFloor); floor != "" {
+		f, err := strconv.Atoi(floor)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("floor %q is not a number", floor))
+		}
+		person.Floor = f
+	} else if adr, ok := card.Get("ADR"); ok && person.Room == "" {
+		components := append(adr.Components(), "", "")
+		if m := roomAndFloorPattern.FindStringSubmatch(components[1]); m != nil {
+			person.Room = m[1]
+			person.Floor, _ = strconv.Atoi(m[2])
+		} else if components[1] != "" {
+			person.Room = components[1]
+		}
+	}
+
+	deptWarning := ""
+	person.DepartmentId, deptWarning = cardDepartment(dir, card)
+	if deptWarning != "" {
+		warnings = append(warnings, deptWarning)
+	}
+
+	contacts, primaryPhone := cardContacts(card)
+	for i, contact := range contacts {
+		if i == primaryPhone {
+			person.Phone = contact.Value
+			continue
+		}
+		person.Contacts = append(person.Contacts, contact)
+	}
+	// A phone that is the only channel needs no contact list
+	if len(person.Contacts) == 0 {
+		person.Contacts = nil
+	}
+
+	return person, warnings, nil
+}
+
+// cardDepartment resolves the department of a card, returning a warning when it cannot
+func cardDepartment(dir services.Directory, card Card) (string, string) {
+	if id := card.Text(propDepartmentId); id != "" {
+		if _, err := dir.GetDepartmentById(id); err == nil {
+			return id, ""
+		}
+	}
+
+	org, ok := card.Get("ORG")
+	if !ok {
+		return "", "card has no ORG, department left empty"
+	}
+	units := org.Components()
+	name := services.NormalizeSearch(strings.TrimSpace(units[len(units)-1]))
+	departments, _ := dir.GetDepartments()
+	for _, dept := range departments {
+		if services.NormalizeSearch(dept.Name) == name {
+			return dept.Id, ""
+		}
+	}
+	return "", fmt.Sprintf("department %q not found, department left empty", units[len(units)-1])
+}
+
+// cardContacts reads TEL and EMAIL properties as contact points and picks the
+// phone that becomes Person.Phone: the preferred work phone, else the first
+// work phone, else the first phone. It returns -1 when there is no phone.
+func cardContacts(card Card) ([]services.ContactPoint, int) {
+	var contacts []services.ContactPoint
+	primaryPhone, firstWork, firstPhone := -1, -1, -1
+
+	for _, p := range card.Properties {
+		var contact services.ContactPoint
+		switch p.Name {
+		case "TEL":
+			contact.Kind = services.ContactOffice
+			switch {
+			case p.HasType("cell"):
+				contact.Kind = services.ContactMobile
+			case p.HasType("fax"):
+				contact.Kind = services.ContactFax
+			}
+			contact.Value = strings.Join(p.Components(), ";")
+			if strings.HasPrefix(strings.ToLower(contact.Value), "tel:") {
+				number, ext, _ := strings.Cut(contact.Value[len("tel:"):], ";ext=")
+				contact.Value = number
+				if ext != "" {
+					contact.Value += " x" + ext
+				}
+			}
+		case "EMAIL":
+			contact.Kind = services.ContactEmail
+			contact.Value = strings.Join(p.Components(), ";")
+		default:
+			continue
+		}
+		contact.Label = p.Param(paramLabel)
+		// vCard 3.0 marks the preferred value with TYPE=pref
+		contact.Primary = p.Param("PREF") == "1" || p.HasType("pref")
+
+		i := len(contacts)
+		contacts = append(contacts, contact)
+		if contact.Kind.IsPhone() && firstPhone < 0 {
+			firstPhone = i
+		}
+		if contact.Kind == services.ContactOffice && firstWork < 0 {
+			firstWork = i
+		}
+		if contact.Kind == services.ContactOffice && contact.Primary && primaryPhone < 0 {
+			primaryPhone = i
+		}
+	}
+
+	switch {
+	case primaryPhone >= 0:
+		return contacts, primaryPhone
+	case firstWork >= 0:
+		return contacts, firstWork
+	}
+	return contacts, firstPhone
+}
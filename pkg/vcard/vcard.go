@@ -0,0 +1,302 @@
+// Package vcard reads and writes vCard 4.0 (RFC 6350) and maps cards to and
+// from people in the directory
+package vcard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line before folding, per RFC 6350
+const maxLineOctets = 75
+
+// Property is a single content line of a card
+type Property struct {
+	Name string
+	// Params holds the parameter values by upper-case parameter name
+	Params map[string][]string
+	// Value is the value as written, with escapes; Components and Card.Text unescape it
+	Value string
+}
+
+// Param returns the first value of a parameter, or ""
+func (p Property) Param(name string) string {
+	if values := p.Params[strings.ToUpper(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// HasType reports whether the TYPE parameter includes t, ignoring case
+func (p Property) HasType(t string) bool {
+	for _, value := range p.Params["TYPE"] {
+		if strings.EqualFold(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Components splits a structured value such as N or ADR into its unescaped components
+func (p Property) Components() []string {
+	var components []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range p.Value {
+		switch {
+		case escaped:
+			b.WriteString(unescapeRune(r))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			components = append(components, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(components, b.String())
+}
+
+// Card is a vCard as an ordered list of properties
+type Card struct {
+	Properties []Property
+}
+
+// Add appends a text property, escaping value
+func (c *Card) Add(name, value string, params map[string][]string) {
+	c.Properties = append(c.Properties, Property{Name: name, Params: params, Value: escapeText(value)})
+}
+
+// AddStructured appends a structured property such as N, ADR or ORG from its components
+func (c *Card) AddStructured(name string, components []string, params map[string][]string) {
+	escaped := make([]string, len(components))
+	for i, component := range components {
+		escaped[i] = escapeText(component)
+	}
+	c.Properties = append(c.Properties, Property{Name: name, Params: params, Value: strings.Join(escaped, ";")})
+}
+
+// Get returns the first property with the given name
+func (c Card) Get(name string) (Property, bool) {
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text returns the unescaped value of the first property with the given name, or ""
+func (c Card) Text(name string) string {
+	if p, ok := c.Get(name); ok {
+		return strings.Join(p.Components(), ";")
+	}
+	return ""
+}
+
+// All returns every property with the given name
+func (c Card) All(name string) []Property {
+	var properties []Property
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			properties = append(properties, p)
+		}
+	}
+	return properties
+}
+
+// Encode writes cards as vCard 4.0 with CRLF line endings and folded long lines
+func Encode(w io.Writer, cards []Card) error {
+	bw := bufio.NewWriter(w)
+	for _, card := range cards {
+		writeLine(bw, "BEGIN:VCARD")
+		writeLine(bw, "VERSION:4.0")
+		for _, p := range card.Properties {
+			writeLine(bw, contentLine(p))
+		}
+		writeLine(bw, "END:VCARD")
+	}
+	return bw.Flush()
+}
+
+// contentLine renders NAME;PARAM=a,b:value
+func contentLine(p Property) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(p.Name))
+	for _, name := range sortedParams(p.Params) {
+		values := make([]string, len(p.Params[name]))
+		for i, value := range p.Params[name] {
+			// Values with separators are quoted
+			if strings.ContainsAny(value, ",;:") {
+				value = `"` + strings.ReplaceAll(value, `"`, "'") + `"`
+			}
+			values[i] = value
+		}
+		b.WriteString(";" + name + "=" + strings.Join(values, ","))
+	}
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// sortedParams returns parameter names with TYPE first and the rest in a stable order
+func sortedParams(params map[string][]string) []string {
+	var names []string
+	for _, name := range []string{"TYPE", "PREF", "VALUE"} {
+		if _, ok := params[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var rest []string
+	for name := range params {
+		if name != "TYPE" && name != "PREF" && name != "VALUE" {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	return append(names, rest...)
+}
+
+// writeLine writes a content line, folding it every 75 octets without splitting a character
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// escapeText escapes a text value per RFC 6350 section 3.4
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func unescapeRune(r rune) string {
+	if r == 'n' || r == 'N' {
+		return "\n"
+	}
+	return string(r)
+}
+
+// Decode reads every card in r. vCard 3.0 cards are accepted as well.
+func Decode(r io.Reader) ([]Card, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []Card
+	var card *Card
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VCARD"):
+			if card != nil {
+				return nil, fmt.Errorf("line %d: BEGIN:VCARD inside a card", n+1)
+			}
+			card = &Card{}
+		case p.Name == "END" && strings.EqualFold(p.Value, "VCARD"):
+			if card == nil {
+				return nil, fmt.Errorf("line %d: END:VCARD without BEGIN", n+1)
+			}
+			cards = append(cards, *card)
+			card = nil
+		case card == nil:
+			return nil, fmt.Errorf("line %d: %s outside a card", n+1, p.Name)
+		case p.Name == "VERSION":
+		default:
+			card.Properties = append(card.Properties, p)
+		}
+	}
+	if card != nil {
+		return nil, fmt.Errorf("card is missing END:VCARD")
+	}
+	return cards, nil
+}
+
+// unfold reads content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine splits [group.]NAME[;PARAM=value,...]:value into a property
+func parseLine(line string) (Property, error) {
+	// The value starts at the first colon outside a quoted parameter value
+	colon, quoted := -1, false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	p := Property{Params: map[string][]string{}, Value: line[colon+1:]}
+	parts := splitUnquoted(line[:colon], ';')
+	p.Name = strings.ToUpper(parts[0])
+	if dot := strings.LastIndex(p.Name, "."); dot >= 0 {
+		p.Name = p.Name[dot+1:]
+	}
+
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// vCard 2.1 style bare types, e.g. TEL;CELL
+			name, value = "TYPE", param
+		}
+		name = strings.ToUpper(name)
+		for _, v := range splitUnquoted(value, ',') {
+			p.Params[name] = append(p.Params[name], strings.Trim(v, `"`))
+		}
+	}
+	return p, nil
+}
+
+// splitUnquoted splits s at sep outside double quotes
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == sep && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,89 @@
+package vcard_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/pkg/vcard"
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// TestRoundTrip exports the bundled directory and imports it back, which must
+// find every card unchanged
+func TestRoundTrip(t *testing.T) {
+	store, err := services.Load(services.NewJSONDirectory("../../data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, store)
+}
+
+// TestRoundTripContacts does the same with every kind of contact point
+func TestRoundTripContacts(t *testing.T) {
+	parent := "root"
+	store := services.NewStore(
+		[]services.Person{
+			{Id: "5c1f8a9e-0000-4000-8000-000000000001", FirstName: "Ayşe", LastName: "Demir", Room: "A-205", Floor: 2, DepartmentId: "eng", Title: "Engineer",
+				Phone: "+90-212-555-1002",
+				Contacts: []services.ContactPoint{
+					{Kind: services.ContactMobile, Value: "+90-532-555-1002", Primary: true},
+					{Kind: services.ContactOffice, Value: "+90-212-555-2002", Label: "lab"},
+					{Kind: services.ContactFax, Value: "+90-212-555-3002"},
+					{Kind: services.ContactEmail, Value: "ayse.demir@example.com"},
+				}},
+			{Id: "5c1f8a9e-0000-4000-8000-000000000002", FirstName: "Ali", LastName: "Yılmaz", DepartmentId: "root",
+				Contacts: []services.ContactPoint{{Kind: services.ContactEmail, Value: "ali@example.com", Label: "secretary"}}},
+		},
+		[]services.Department{
+			{Id: "root", Name: "Genel Müdürlük", ManagerId: "5c1f8a9e-0000-4000-8000-000000000002"},
+			{Id: "eng", Name: "Bilgi İşlem", ParentDepartmentId: &parent},
+		},
+	)
+	roundTrip(t, store)
+}
+
+// roundTrip encodes every person in store as a card, decodes the cards and
+// plans importing them into store, which must leave everyone unchanged
+func roundTrip(t *testing.T, store *services.Store) {
+	t.Helper()
+	people, err := store.GetPeople()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cards := make([]vcard.Card, len(people))
+	for i, person := range people {
+		cards[i] = vcard.FromPerson(store, person)
+	}
+	var buf bytes.Buffer
+	if err := vcard.Encode(&buf, cards); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := vcard.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(people) {
+		t.Fatalf("decoded %d cards, want %d", len(decoded), len(people))
+	}
+	items := make([]services.ImportItem, len(decoded))
+	for i, card := range decoded {
+		person, warnings, err := vcard.ToPerson(store, card)
+		items[i] = services.ImportItem{Source: fmt.Sprintf("card %d", i+1), Person: person, Warnings: warnings, Err: err}
+	}
+
+	results, err := services.PlanImport(store, items, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Action != services.ImportUnchanged {
+			t.Errorf("%s (%s): %s %s, changes %+v", r.Source, r.Person.FullName(), r.Action, r.Reason, r.Changes)
+		}
+		if len(r.Warnings) > 0 {
+			t.Errorf("%s (%s): warnings %v", r.Source, r.Person.FullName(), r.Warnings)
+		}
+	}
+}
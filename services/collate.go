@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// turkishBase maps Turkish letters to the ASCII letter typed for them on an ASCII keyboard
+var turkishBase = map[rune]rune{
+	'ı': 'i', 'ş': 's', 'ğ': 'g', 'ç': 'c', 'ö': 'o', 'ü': 'u',
+	'â': 'a', 'î': 'i', 'û': 'u',
+}
+
+// NormalizeSearch folds s for diacritic-insensitive matching: Turkish case
+// folding (I → ı, İ → i) followed by mapping every letter to its base letter,
+// so "ŞAHİN", "Şahin" and "sahin" all become "sahin". The mapping is rune for
+// rune, so rune offsets in the result match offsets in s.
+func NormalizeSearch(s string) string {
+	return strings.Map(normalizeRune, s)
+}
+
+func normalizeRune(r rune) rune {
+	r = unicode.TurkishCase.ToLower(r)
+	if r < 0x80 {
+		return r
+	}
+	if base, ok := turkishBase[r]; ok {
+		return base
+	}
+
+	// Other accented letters fall back to their canonical decomposition's base rune
+	decomposed := []rune(norm.NFD.String(string(r)))
+	if len(decomposed) > 1 && unicode.IsLetter(decomposed[0]) {
+		return decomposed[0]
+	}
+	return r
+}
+
+// NewCollator returns a case-insensitive collator using the Turkish alphabet
+// (… c ç d … g ğ h ı i … o ö … s ş t u ü …). Collators are not safe for concurrent use.
+func NewCollator() *collate.Collator {
+	return collate.New(language.Turkish, collate.IgnoreCase)
+}
+
+// CompareTurkish compares a and b in Turkish alphabetical order
+func CompareTurkish(a, b string) int {
+	return NewCollator().CompareString(a, b)
+}
+
+// SortPeople sorts people by last name, then first name, in Turkish alphabetical order
+func SortPeople(people []Person) {
+	sortCollated(people,
+		func(p Person) string { return p.LastName },
+		func(p Person) string { return p.FirstName })
+}
+
+// SortDepartments sorts departments by name in Turkish alphabetical order
+func SortDepartments(departments []Department) {
+	sortCollated(departments, func(d Department) string { return d.Name })
+}
+
+// sortCollated stably sorts s by the given fields in Turkish alphabetical
+// order. Collation keys are computed once per element, which is much faster
+// than comparing strings with the collator on every comparison.
+func sortCollated[T any](s []T, fields ...func(T) string) {
+	type entry struct {
+		keys  [][]byte
+		index int
+	}
+	c := NewCollator()
+	var buf collate.Buffer
+	entries := make([]entry, len(s))
+	for i, v := range s {
+		entries[i] = entry{keys: make([][]byte, len(fields)), index: i}
+		for j, field := range fields {
+			entries[i].keys[j] = c.KeyFromString(&buf, field(v))
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		for j := range a.keys {
+			if n := bytes.Compare(a.keys[j], b.keys[j]); n != 0 {
+				return n
+			}
+		}
+		return 0
+	})
+	sorted := make([]T, len(s))
+	for i, e := range entries {
+		sorted[i] = s[e.index]
+	}
+	copy(s, sorted)
+}
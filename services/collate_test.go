@@ -0,0 +1,95 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeSearch(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"ŞAHİN", "sahin"},
+		{"Şahin", "sahin"},
+		{"IŞIK", "isik"},
+		{"ılık", "ilik"},
+		{"İstanbul", "istanbul"},
+		{"ÇAĞLAR", "caglar"},
+		{"Doğan", "dogan"},
+		{"Öztürk", "ozturk"},
+		{"Hâkim", "hakim"},
+		{"José", "jose"},
+		{"A-205", "a-205"},
+	} {
+		got := NormalizeSearch(tt.in)
+		if got != tt.want {
+			t.Errorf("NormalizeSearch(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if len([]rune(got)) != len([]rune(tt.in)) {
+			t.Errorf("NormalizeSearch(%q) has %d runes, want %d", tt.in, len([]rune(got)), len([]rune(tt.in)))
+		}
+	}
+}
+
+// turkishOrder is sorted in Turkish alphabetical order. Folding to ASCII
+// would put Çağlar before Cuma, Şahin before Sarı and İdil before Işık.
+var turkishOrder = []string{"Agah", "Ağa", "Cuma", "Çağlar", "Demir", "ılgın", "Işık", "İdil", "inan", "Ozan", "Öz", "Sarı", "Şahin", "Tan", "Uysal", "Ünal"}
+
+func TestCompareTurkish(t *testing.T) {
+	for i := 1; i < len(turkishOrder); i++ {
+		a, b := turkishOrder[i-1], turkishOrder[i]
+		if got := CompareTurkish(a, b); got >= 0 {
+			t.Errorf("CompareTurkish(%q, %q) = %d, want < 0", a, b, got)
+		}
+		if got := CompareTurkish(b, a); got <= 0 {
+			t.Errorf("CompareTurkish(%q, %q) = %d, want > 0", b, a, got)
+		}
+	}
+
+	// Case is ignored, with the Turkish pairs I/ı and İ/i
+	for _, pair := range [][2]string{{"IŞIK", "ışık"}, {"İNCE", "ince"}, {"ÇAĞLAR", "çağlar"}} {
+		if got := CompareTurkish(pair[0], pair[1]); got != 0 {
+			t.Errorf("CompareTurkish(%q, %q) = %d, want 0", pair[0], pair[1], got)
+		}
+	}
+}
+
+func TestSortDepartments(t *testing.T) {
+	departments := make([]Department, len(turkishOrder))
+	for i, name := range turkishOrder {
+		// Reversed, so every pair has to move
+		departments[len(departments)-1-i] = Department{Id: name, Name: name}
+	}
+
+	SortDepartments(departments)
+	got := make([]string, len(departments))
+	for i, dept := range departments {
+		got[i] = dept.Name
+	}
+	if !slices.Equal(got, turkishOrder) {
+		t.Errorf("SortDepartments order is %v, want %v", got, turkishOrder)
+	}
+}
+
+func TestSortPeople(t *testing.T) {
+	people := []Person{
+		{Id: "1", FirstName: "Zeynep", LastName: "Şahin"},
+		{Id: "2", FirstName: "Ali", LastName: "Sarı"},
+		{Id: "3", FirstName: "Çetin", LastName: "Şahin"},
+		{Id: "4", FirstName: "Can", LastName: "Şahin"},
+		{Id: "5", FirstName: "Işıl", LastName: "Çelik"},
+		{Id: "6", FirstName: "İpek", LastName: "Çelik"},
+		{Id: "7", FirstName: "Ali", LastName: "Sarı"},
+	}
+
+	SortPeople(people)
+	var got []string
+	for _, p := range people {
+		got = append(got, p.Id)
+	}
+	// Last name first, then first name; equal names keep their order
+	want := []string{"5", "6", "2", "7", "4", "3", "1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("SortPeople order is %v, want %v", got, want)
+	}
+}
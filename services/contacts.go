@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+)
+
+// ContactKind is the channel a contact point is reached through
+type ContactKind string
+
+const (
+	ContactOffice ContactKind = "office"
+	ContactMobile ContactKind = "mobile"
+	ContactFax    ContactKind = "fax"
+	ContactEmail  ContactKind = "email"
+)
+
+// ContactKinds lists every contact kind
+var ContactKinds = []ContactKind{ContactOffice, ContactMobile, ContactFax, ContactEmail}
+
+// IsPhone reports whether the kind is reached by a phone number
+func (k ContactKind) IsPhone() bool {
+	return k == ContactOffice || k == ContactMobile || k == ContactFax
+}
+
+// ContactPoint is one way to reach a person
+type ContactPoint struct {
+	Kind  ContactKind `json:"kind"`
+	Value string      `json:"value"`
+	// Label tells contact points of the same kind apart, e.g. "lab" or "secretary"
+	Label   string `json:"label,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// FormattedValue returns the value, with phone numbers in a display style
+func (c ContactPoint) FormattedValue(style PhoneStyle) string {
+	if c.Kind.IsPhone() {
+		return FormatPhone(c.Value, style)
+	}
+	return c.Value
+}
+
+// Name returns the label, or the kind when there is no label
+func (c ContactPoint) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return string(c.Kind)
+}
+
+// ContactPoints returns every channel of the person with exactly one marked
+// primary. The Phone field is listed first as the office phone unless it is
+// already among Contacts, and is the primary channel unless another one is.
+func (p Person) ContactPoints() []ContactPoint {
+	points := slices.Clone(p.Contacts)
+	if p.Phone != "" && !slices.ContainsFunc(points, func(c ContactPoint) bool {
+		return c.Kind.IsPhone() && NationalNumber(c.Value) == NationalNumber(p.Phone)
+	}) {
+		points = slices.Insert(points, 0, ContactPoint{Kind: ContactOffice, Value: p.Phone})
+	}
+
+	primary := slices.IndexFunc(points, func(c ContactPoint) bool { return c.Primary })
+	if primary < 0 {
+		primary = 0
+	}
+	for i := range points {
+		points[i].Primary = i == primary
+	}
+	return points
+}
+
+// PrimaryContact returns the primary channel of the person, if any
+func (p Person) PrimaryContact() (ContactPoint, bool) {
+	for _, c := range p.ContactPoints() {
+		if c.Primary {
+			return c, true
+		}
+	}
+	return ContactPoint{}, false
+}
+
+// UnmarshalJSON reads a person, taking Phone from the contact points when the
+// phone field is missing so code using Phone keeps working
+func (p *Person) UnmarshalJSON(data []byte) error {
+	// person has the fields of Person without this method
+	type person Person
+	if err := json.Unmarshal(data, (*person)(p)); err != nil {
+		return err
+	}
+	p.syncPhone()
+	return nil
+}
+
+// syncPhone fills an empty Phone with the primary phone contact, or the first one
+func (p *Person) syncPhone() {
+	if p.Phone != "" {
+		return
+	}
+	first := -1
+	for i, c := range p.Contacts {
+		if !c.Kind.IsPhone() {
+			continue
+		}
+		if c.Primary {
+			p.Phone = c.Value
+			return
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		p.Phone = p.Contacts[first].Value
+	}
+}
+
+// validateContact checks a contact point's kind and value
+func validateContact(c ContactPoint) error {
+	if !slices.Contains(ContactKinds, c.Kind) {
+		names := make([]string, len(ContactKinds))
+		for i, kind := range ContactKinds {
+			names[i] = string(kind)
+		}
+		return fmt.Errorf("unknown contact kind %q, expected one of %s", c.Kind, strings.Join(names, ", "))
+	}
+	if strings.TrimSpace(c.Value) == "" {
+		return fmt.Errorf("%s contact is empty", c.Kind)
+	}
+	if c.Kind.IsPhone() {
+		if _, err := ParsePhone(c.Value); err != nil {
+			return err
+		}
+	}
+	if c.Kind == ContactEmail {
+		if addr, err := mail.ParseAddress(c.Value); err != nil || addr.Address != c.Value {
+			return fmt.Errorf("email address %q is not valid", c.Value)
+		}
+	}
+	return nil
+}
@@ -4,32 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
 // GetDepartments reads and returns all departments from the JSON file
-func GetDepartments() ([]Department, error) {
-	// Get the path to the data directory relative to the project root
-	dataPath := filepath.Join("data", "departments.json")
-
+func (d *JSONDirectory) GetDepartments() ([]Department, error) {
 	// Read the file
-	data, err := os.ReadFile(dataPath)
+	data, err := os.ReadFile(d.DepartmentsPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read departments.json: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", d.DepartmentsPath, err)
 	}
 
 	// Unmarshal JSON data
 	var departments []Department
 	if err := json.Unmarshal(data, &departments); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal departments.json: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", d.DepartmentsPath, err)
 	}
 
 	return departments, nil
 }
 
 // GetDepartmentById finds a department by its Id
-func GetDepartmentById(id string) (*Department, error) {
-	departments, err := GetDepartments()
+func (d *JSONDirectory) GetDepartmentById(id string) (*Department, error) {
+	departments, err := d.GetDepartments()
 	if err != nil {
 		return nil, err
 	}
@@ -44,15 +40,15 @@ func GetDepartmentById(id string) (*Department, error) {
 }
 
 // GetDepartmentsByParentId returns all departments with a specific parent department Id
-func GetDepartmentsByParentId(parentId string) ([]Department, error) {
-	departments, err := GetDepartments()
+func (d *JSONDirectory) GetDepartmentsByParentId(parentId string) ([]Department, error) {
+	departments, err := d.GetDepartments()
 	if err != nil {
 		return nil, err
 	}
 
 	var result []Department
 	for i := range departments {
-		if departments[i].ParentDepartmentId != nil && *departments[i].ParentDepartmentId == parentId {
+		if departments[i].HasParent() && *departments[i].ParentDepartmentId == parentId {
 			result = append(result, departments[i])
 		}
 	}
@@ -61,18 +57,78 @@ func GetDepartmentsByParentId(parentId string) ([]Department, error) {
 }
 
 // GetTopLevelDepartments returns all departments without a parent (top-level departments)
-func GetTopLevelDepartments() ([]Department, error) {
-	departments, err := GetDepartments()
+func (d *JSONDirectory) GetTopLevelDepartments() ([]Department, error) {
+	departments, err := d.GetDepartments()
 	if err != nil {
 		return nil, err
 	}
 
 	var result []Department
 	for i := range departments {
-		if departments[i].ParentDepartmentId == nil {
+		if !departments[i].HasParent() {
 			result = append(result, departments[i])
 		}
 	}
 
 	return result, nil
 }
+
+// CreateDepartment validates a new department and appends it to the JSON file
+func (d *JSONDirectory) CreateDepartment(dept Department) (*Department, error) {
+	people, departments, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if dept.Id == "" {
+		dept.Id = newId()
+	} else if findDepartment(departments, dept.Id) >= 0 {
+		return nil, fmt.Errorf("department with Id %s already exists", dept.Id)
+	}
+	if err := validateDepartment(dept, people, departments); err != nil {
+		return nil, err
+	}
+
+	if err := writeJSONAtomic(d.DepartmentsPath, append(departments, dept)); err != nil {
+		return nil, err
+	}
+
+	return &dept, nil
+}
+
+// UpdateDepartment validates and replaces the department with the same Id in the JSON file
+func (d *JSONDirectory) UpdateDepartment(dept Department) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	i := findDepartment(departments, dept.Id)
+	if i < 0 {
+		return fmt.Errorf("department with Id %s not found", dept.Id)
+	}
+	if err := validateDepartment(dept, people, departments); err != nil {
+		return err
+	}
+
+	departments[i] = dept
+	return writeJSONAtomic(d.DepartmentsPath, departments)
+}
+
+// DeleteDepartment removes a department from the JSON file
+func (d *JSONDirectory) DeleteDepartment(id string) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	i := findDepartment(departments, id)
+	if i < 0 {
+		return fmt.Errorf("department with Id %s not found", id)
+	}
+	if err := checkDepartmentDeletable(id, people, departments); err != nil {
+		return err
+	}
+
+	return writeJSONAtomic(d.DepartmentsPath, append(departments[:i], departments[i+1:]...))
+}
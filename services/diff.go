@@ -0,0 +1,230 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldChange is a field that differs between two versions of a record
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+	// OldName and NewName name the records an Id field refers to, when known
+	OldName string `json:"oldName,omitempty" yaml:"oldName,omitempty"`
+	NewName string `json:"newName,omitempty" yaml:"newName,omitempty"`
+}
+
+// RecordDiff is a person or department that was added, removed or modified
+type RecordDiff struct {
+	Id   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	// Changes lists the modified fields; it is empty for added and removed records
+	Changes []FieldChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// RecordDiffs groups the records of one kind by what happened to them
+type RecordDiffs struct {
+	Added    []RecordDiff `json:"added" yaml:"added"`
+	Removed  []RecordDiff `json:"removed" yaml:"removed"`
+	Modified []RecordDiff `json:"modified" yaml:"modified"`
+}
+
+// Count returns the number of added, removed and modified records
+func (d RecordDiffs) Count() int {
+	return len(d.Added) + len(d.Removed) + len(d.Modified)
+}
+
+// DirectoryDiff is the difference between two versions of the directory
+type DirectoryDiff struct {
+	People      RecordDiffs `json:"people" yaml:"people"`
+	Departments RecordDiffs `json:"departments" yaml:"departments"`
+}
+
+// Empty reports whether the two versions are the same
+func (d DirectoryDiff) Empty() bool {
+	return d.People.Count() == 0 && d.Departments.Count() == 0
+}
+
+// DiffDirectories compares two versions of the directory, matching records
+// by Id. References to departments and managers are named using the version
+// each side comes from, so moves read as department names.
+func DiffDirectories(old, new Directory) (*DirectoryDiff, error) {
+	oldPeople, oldDepartments, err := loadAll(old)
+	if err != nil {
+		return nil, err
+	}
+	newPeople, newDepartments, err := loadAll(new)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorting first keeps the indexes valid and the output in name order
+	SortPeople(oldPeople)
+	SortPeople(newPeople)
+	SortDepartments(oldDepartments)
+	SortDepartments(newDepartments)
+	oldPeopleById, newPeopleById := personIndex(oldPeople), personIndex(newPeople)
+	oldDepartmentsById, newDepartmentsById := departmentIndex(oldDepartments), departmentIndex(newDepartments)
+
+	departmentName := func(departments []Department, index map[string]int, id string) string {
+		if i, ok := index[id]; ok {
+			return departments[i].Name
+		}
+		return ""
+	}
+	personName := func(people []Person, index map[string]int, id string) string {
+		if i, ok := index[id]; ok {
+			return people[i].FullName()
+		}
+		return ""
+	}
+	name := func(changes []FieldChange) {
+		for i, change := range changes {
+			switch change.Field {
+			case "departmentId", "parentDepartmentId":
+				changes[i].OldName = departmentName(oldDepartments, oldDepartmentsById, change.Old)
+				changes[i].NewName = departmentName(newDepartments, newDepartmentsById, change.New)
+			case "managerId":
+				changes[i].OldName = personName(oldPeople, oldPeopleById, change.Old)
+				changes[i].NewName = personName(newPeople, newPeopleById, change.New)
+			}
+		}
+	}
+
+	// Empty lists rather than nil keep the JSON form regular
+	empty := func() RecordDiffs {
+		return RecordDiffs{Added: []RecordDiff{}, Removed: []RecordDiff{}, Modified: []RecordDiff{}}
+	}
+	diff := DirectoryDiff{People: empty(), Departments: empty()}
+	for _, person := range oldPeople {
+		if _, ok := newPeopleById[person.Id]; !ok {
+			diff.People.Removed = append(diff.People.Removed, RecordDiff{Id: person.Id, Name: person.FullName()})
+		}
+	}
+	for _, person := range newPeople {
+		i, ok := oldPeopleById[person.Id]
+		if !ok {
+			diff.People.Added = append(diff.People.Added, RecordDiff{Id: person.Id, Name: person.FullName()})
+			continue
+		}
+		if changes := DiffPerson(oldPeople[i], person); len(changes) > 0 {
+			name(changes)
+			diff.People.Modified = append(diff.People.Modified, RecordDiff{Id: person.Id, Name: person.FullName(), Changes: changes})
+		}
+	}
+
+	for _, dept := range oldDepartments {
+		if _, ok := newDepartmentsById[dept.Id]; !ok {
+			diff.Departments.Removed = append(diff.Departments.Removed, RecordDiff{Id: dept.Id, Name: dept.Name})
+		}
+	}
+	for _, dept := range newDepartments {
+		i, ok := oldDepartmentsById[dept.Id]
+		if !ok {
+			diff.Departments.Added = append(diff.Departments.Added, RecordDiff{Id: dept.Id, Name: dept.Name})
+			continue
+		}
+		if changes := DiffDepartment(oldDepartments[i], dept); len(changes) > 0 {
+			name(changes)
+			diff.Departments.Modified = append(diff.Departments.Modified, RecordDiff{Id: dept.Id, Name: dept.Name, Changes: changes})
+		}
+	}
+
+	return &diff, nil
+}
+
+// loadAll reads every person and department of dir
+func loadAll(dir Directory) ([]Person, []Department, error) {
+	people, err := dir.GetPeople()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load people: %w", err)
+	}
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+	return people, departments, nil
+}
+
+// DiffPerson lists the fields that differ between two versions of a person,
+// using the JSON field names
+func DiffPerson(old, new Person) []FieldChange {
+	var changes []FieldChange
+	compare := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, FieldChange{Field: field, Old: a, New: b})
+		}
+	}
+	compare("prefix", stringValue(old.Prefix), stringValue(new.Prefix))
+	compare("firstName", old.FirstName, new.FirstName)
+	compare("lastName", old.LastName, new.LastName)
+	compare("title", old.Title, new.Title)
+	compare("departmentId", old.DepartmentId, new.DepartmentId)
+	compare("room", old.Room, new.Room)
+	compare("floor", strconv.Itoa(old.Floor), strconv.Itoa(new.Floor))
+	if NationalNumber(old.Phone) != NationalNumber(new.Phone) {
+		compare("phone", old.Phone, new.Phone)
+	}
+	// Contacts are compared as channels without the phone, which is compared
+	// above, so a phone kept only in Phone equals the same phone kept in Contacts
+	if oldContacts, newContacts := otherContacts(old), otherContacts(new); !slices.EqualFunc(oldContacts, newContacts, sameContact) {
+		compare("contacts", formatContacts(oldContacts), formatContacts(newContacts))
+	}
+	return changes
+}
+
+// DiffDepartment lists the fields that differ between two versions of a
+// department, using the JSON field names
+func DiffDepartment(old, new Department) []FieldChange {
+	var changes []FieldChange
+	compare := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, FieldChange{Field: field, Old: a, New: b})
+		}
+	}
+	compare("name", old.Name, new.Name)
+	if NationalNumber(old.Phone) != NationalNumber(new.Phone) {
+		compare("phone", old.Phone, new.Phone)
+	}
+	compare("managerId", old.ManagerId, new.ManagerId)
+	compare("parentDepartmentId", stringValue(old.ParentDepartmentId), stringValue(new.ParentDepartmentId))
+	return changes
+}
+
+// sameContact compares contact points, ignoring how phone numbers are written
+func sameContact(a, b ContactPoint) bool {
+	if a.Kind.IsPhone() && b.Kind.IsPhone() {
+		a.Value, b.Value = NationalNumber(a.Value), NationalNumber(b.Value)
+	}
+	return a == b
+}
+
+// otherContacts returns the contact points of p except its Phone
+func otherContacts(p Person) []ContactPoint {
+	phone := NationalNumber(p.Phone)
+	return slices.DeleteFunc(p.ContactPoints(), func(c ContactPoint) bool {
+		return p.Phone != "" && c.Kind.IsPhone() && NationalNumber(c.Value) == phone
+	})
+}
+
+// formatContacts renders contact points as "kind: value" joined by "; "
+func formatContacts(contacts []ContactPoint) string {
+	parts := make([]string, len(contacts))
+	for i, c := range contacts {
+		parts[i] = c.Name() + ": " + c.Value
+		if c.Primary {
+			parts[i] += " (primary)"
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffPerson(t *testing.T) {
+	base := Person{Id: "1", FirstName: "Ayşe", LastName: "Demir", Phone: "+90-212-555-1002"}
+	with := func(change func(p *Person)) Person {
+		p := base
+		change(&p)
+		return p
+	}
+
+	tests := []struct {
+		name string
+		new  Person
+		want []string
+	}{
+		{"unchanged", base, nil},
+		{"phone reformatted", with(func(p *Person) { p.Phone = "0212 555 10 02" }), nil},
+		{"phone changed", with(func(p *Person) { p.Phone = "+90 212 555 9999" }), []string{"phone"}},
+		{"phone moved into contacts", with(func(p *Person) {
+			p.Contacts = []ContactPoint{{Kind: ContactOffice, Value: "+90 212 555 1002"}}
+		}), nil},
+		{"phone changed with other contacts", with(func(p *Person) {
+			p.Phone = "+90 212 555 9999"
+			p.Contacts = []ContactPoint{{Kind: ContactEmail, Value: "ayse@example.org"}}
+		}), []string{"phone", "contacts"}},
+		{"primary changed", with(func(p *Person) {
+			p.Contacts = []ContactPoint{{Kind: ContactEmail, Value: "ayse@example.org", Primary: true}}
+		}), []string{"contacts"}},
+		{"room and department", with(func(p *Person) { p.Room, p.DepartmentId = "A-210", "eng" }), []string{"departmentId", "room"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, change := range DiffPerson(base, tt.new) {
+				fields = append(fields, change.Field)
+			}
+			if !slices.Equal(fields, tt.want) {
+				t.Errorf("changed fields are %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import "path/filepath"
+
+// Directory is the data source for people and departments
+type Directory interface {
+	// GetPeople returns all people
+	GetPeople() ([]Person, error)
+	// GetPersonById finds a person by their Id
+	GetPersonById(id string) (*Person, error)
+	// GetPeopleByDepartmentId returns all people in a specific department
+	GetPeopleByDepartmentId(departmentId string) ([]Person, error)
+
+	// GetDepartments returns all departments
+	GetDepartments() ([]Department, error)
+	// GetDepartmentById finds a department by its Id
+	GetDepartmentById(id string) (*Department, error)
+	// GetDepartmentsByParentId returns all departments with a specific parent department Id
+	GetDepartmentsByParentId(parentId string) ([]Department, error)
+	// GetTopLevelDepartments returns all departments without a parent, including
+	// those whose ParentDepartmentId is empty
+	GetTopLevelDepartments() ([]Department, error)
+}
+
+// JSONDirectory is a Directory backed by people.json and departments.json files
+type JSONDirectory struct {
+	PeoplePath      string
+	DepartmentsPath string
+}
+
+// NewJSONDirectory creates a JSON directory reading the data files inside dir
+func NewJSONDirectory(dir string) *JSONDirectory {
+	return &JSONDirectory{
+		PeoplePath:      filepath.Join(dir, "people.json"),
+		DepartmentsPath: filepath.Join(dir, "departments.json"),
+	}
+}
+
+var (
+	_ Editor = (*JSONDirectory)(nil)
+	_ Editor = (*SQLiteDirectory)(nil)
+)
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CycleError reports a cycle found while walking the hierarchy
+type CycleError struct {
+	// Ids lists the records on the cycle in the order they were reached
+	Ids []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("hierarchy contains a cycle: %s", strings.Join(e.Ids, " → "))
+}
+
+// Ancestors returns the parent departments of a department, nearest first.
+// The walk stops at a parent that does not exist; Validate reports those.
+func Ancestors(dir Directory, deptId string) ([]Department, error) {
+	dept, err := dir.GetDepartmentById(deptId)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []Department
+	path := []string{dept.Id}
+	seen := map[string]bool{dept.Id: true}
+	for dept.ParentDepartmentId != nil {
+		parentId := *dept.ParentDepartmentId
+		if seen[parentId] {
+			return ancestors, &CycleError{Ids: append(path, parentId)}
+		}
+		parent, err := dir.GetDepartmentById(parentId)
+		if err != nil {
+			break
+		}
+		seen[parentId] = true
+		path = append(path, parentId)
+		ancestors = append(ancestors, *parent)
+		dept = parent
+	}
+	return ancestors, nil
+}
+
+// Descendants returns every department below a department, each followed by its own descendants
+func Descendants(dir Directory, deptId string) ([]Department, error) {
+	if _, err := dir.GetDepartmentById(deptId); err != nil {
+		return nil, err
+	}
+
+	var descendants []Department
+	seen := map[string]bool{deptId: true}
+	var walk func(id string, path []string) error
+	walk = func(id string, path []string) error {
+		children, err := dir.GetDepartmentsByParentId(id)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if seen[child.Id] {
+				return &CycleError{Ids: append(path, child.Id)}
+			}
+			seen[child.Id] = true
+			descendants = append(descendants, child)
+			if err := walk(child.Id, append(path, child.Id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := walk(deptId, []string{deptId})
+	return descendants, err
+}
+
+// Manager returns the person a person reports to: the manager of their
+// department, or for the department's own manager, the manager of the nearest
+// parent department managed by someone else. It returns nil at the top of the
+// hierarchy or when the manager does not exist.
+func Manager(dir Directory, personId string) (*Person, error) {
+	person, err := dir.GetPersonById(personId)
+	if err != nil {
+		return nil, err
+	}
+
+	dept, err := dir.GetDepartmentById(person.DepartmentId)
+	if err != nil {
+		return nil, nil
+	}
+	path := []string{dept.Id}
+	seen := map[string]bool{dept.Id: true}
+	for dept.ManagerId == person.Id {
+		if dept.ParentDepartmentId == nil {
+			return nil, nil
+		}
+		parentId := *dept.ParentDepartmentId
+		if seen[parentId] {
+			return nil, &CycleError{Ids: append(path, parentId)}
+		}
+		if dept, err = dir.GetDepartmentById(parentId); err != nil {
+			return nil, nil
+		}
+		seen[parentId] = true
+		path = append(path, parentId)
+	}
+
+	manager, err := dir.GetPersonById(dept.ManagerId)
+	if err != nil {
+		return nil, nil
+	}
+	return manager, nil
+}
+
+// ManagerChain returns a person's manager, that manager's manager and so on
+// up to the top of the hierarchy
+func ManagerChain(dir Directory, personId string) ([]Person, error) {
+	if _, err := dir.GetPersonById(personId); err != nil {
+		return nil, err
+	}
+
+	var chain []Person
+	path := []string{personId}
+	seen := map[string]bool{personId: true}
+	for id := personId; ; {
+		manager, err := Manager(dir, id)
+		if err != nil || manager == nil {
+			return chain, err
+		}
+		if seen[manager.Id] {
+			return chain, &CycleError{Ids: append(path, manager.Id)}
+		}
+		seen[manager.Id] = true
+		path = append(path, manager.Id)
+		chain = append(chain, *manager)
+		id = manager.Id
+	}
+}
+
+// CommonAncestor returns the lowest department that contains both departments,
+// which is one of them when it contains the other. It returns nil when they
+// are in separate trees.
+func CommonAncestor(dir Directory, a, b string) (*Department, error) {
+	deptA, err := dir.GetDepartmentById(a)
+	if err != nil {
+		return nil, err
+	}
+	ancestorsA, err := Ancestors(dir, a)
+	if err != nil {
+		return nil, err
+	}
+	lineA := map[string]bool{deptA.Id: true}
+	for _, dept := range ancestorsA {
+		lineA[dept.Id] = true
+	}
+
+	deptB, err := dir.GetDepartmentById(b)
+	if err != nil {
+		return nil, err
+	}
+	ancestorsB, err := Ancestors(dir, b)
+	if err != nil {
+		return nil, err
+	}
+	for _, dept := range append([]Department{*deptB}, ancestorsB...) {
+		if lineA[dept.Id] {
+			return &dept, nil
+		}
+	}
+	return nil, nil
+}
+
+// Subordinates returns the people who report to a person, as defined by
+// Manager. With recursive, their subordinates follow, each person once.
+func Subordinates(dir Directory, personId string, recursive bool) ([]Person, error) {
+	if _, err := dir.GetPersonById(personId); err != nil {
+		return nil, err
+	}
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reports are found once per person, which also guards against cycles
+	seen := map[string]bool{personId: true}
+	var subordinates []Person
+	queue := []string{personId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		reports, err := directReports(dir, departments, id)
+		if err != nil {
+			return subordinates, err
+		}
+		for _, report := range reports {
+			if seen[report.Id] {
+				continue
+			}
+			seen[report.Id] = true
+			subordinates = append(subordinates, report)
+			if recursive {
+				queue = append(queue, report.Id)
+			}
+		}
+	}
+	return subordinates, nil
+}
+
+// directReports returns the members of the departments a person manages and
+// the managers of their sub-departments
+func directReports(dir Directory, departments []Department, personId string) ([]Person, error) {
+	var reports []Person
+	for _, dept := range departments {
+		if dept.ManagerId != personId {
+			continue
+		}
+
+		members, err := dir.GetPeopleByDepartmentId(dept.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member.Id != personId {
+				reports = append(reports, member)
+			}
+		}
+
+		children, err := dir.GetDepartmentsByParentId(dept.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			// Sub-departments the person also manages are handled on their own
+			if child.ManagerId == personId {
+				continue
+			}
+			if manager, err := dir.GetPersonById(child.ManagerId); err == nil {
+				reports = append(reports, *manager)
+			}
+		}
+	}
+	return reports, nil
+}
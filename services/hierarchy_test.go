@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// hierarchyFixture is a directory with a normal tree and the broken shapes
+// the traversals must survive:
+//
+//	root ─ eng ─ web          tree, managed by ali, ayse and can
+//	loopA ⇄ loopB             parent cycle
+//	self → self               self-reference
+//	orphan → missing          missing parent
+//	mgrA, mgrB                manager cycle: each is managed by the other's manager
+func hierarchyFixture() *Store {
+	parent := func(id string) *string { return &id }
+	departments := []Department{
+		{Id: "root", Name: "Root", ManagerId: "ali"},
+		{Id: "eng", Name: "Engineering", ManagerId: "ayse", ParentDepartmentId: parent("root")},
+		{Id: "web", Name: "Web", ManagerId: "can", ParentDepartmentId: parent("eng")},
+		{Id: "loopA", Name: "Loop A", ManagerId: "deniz", ParentDepartmentId: parent("loopB")},
+		{Id: "loopB", Name: "Loop B", ManagerId: "ece", ParentDepartmentId: parent("loopA")},
+		{Id: "self", Name: "Self", ManagerId: "fatma", ParentDepartmentId: parent("self")},
+		{Id: "orphan", Name: "Orphan", ManagerId: "gul", ParentDepartmentId: parent("missing")},
+		{Id: "mgrA", Name: "Managers A", ManagerId: "hakan"},
+		{Id: "mgrB", Name: "Managers B", ManagerId: "irem"},
+	}
+	people := []Person{
+		{Id: "ali", FirstName: "Ali", LastName: "Yılmaz", DepartmentId: "root"},
+		{Id: "ayse", FirstName: "Ayşe", LastName: "Demir", DepartmentId: "eng"},
+		{Id: "burak", FirstName: "Burak", LastName: "Kaya", DepartmentId: "eng"},
+		{Id: "can", FirstName: "Can", LastName: "Şahin", DepartmentId: "web"},
+		{Id: "cem", FirstName: "Cem", LastName: "Öz", DepartmentId: "web"},
+		{Id: "deniz", FirstName: "Deniz", LastName: "Ak", DepartmentId: "loopA"},
+		{Id: "ece", FirstName: "Ece", LastName: "Kara", DepartmentId: "loopB"},
+		{Id: "fatma", FirstName: "Fatma", LastName: "Çelik", DepartmentId: "self"},
+		{Id: "gul", FirstName: "Gül", LastName: "Aydın", DepartmentId: "orphan"},
+		// hakan manages mgrA but works in mgrB, and irem the other way round
+		{Id: "hakan", FirstName: "Hakan", LastName: "Güler", DepartmentId: "mgrB"},
+		{Id: "irem", FirstName: "İrem", LastName: "Doğan", DepartmentId: "mgrA"},
+	}
+	return NewStore(people, departments)
+}
+
+func departmentIds(departments []Department) []string {
+	ids := make([]string, len(departments))
+	for i, dept := range departments {
+		ids[i] = dept.Id
+	}
+	return ids
+}
+
+func personIds(people []Person) []string {
+	ids := make([]string, len(people))
+	for i, person := range people {
+		ids[i] = person.Id
+	}
+	return ids
+}
+
+// checkErr fails the test unless err is nil when wantCycle is false, or a
+// *CycleError when it is true
+func checkErr(t *testing.T, err error, wantCycle bool) {
+	t.Helper()
+	var cycle *CycleError
+	switch {
+	case wantCycle && !errors.As(err, &cycle):
+		t.Errorf("got error %v, want a cycle error", err)
+	case !wantCycle && err != nil:
+		t.Errorf("got error %v, want none", err)
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		deptId    string
+		want      []string
+		wantCycle bool
+	}{
+		{name: "top level", deptId: "root", want: []string{}},
+		{name: "nearest first", deptId: "web", want: []string{"eng", "root"}},
+		{name: "parent cycle", deptId: "loopA", want: []string{"loopB"}, wantCycle: true},
+		{name: "self-reference", deptId: "self", want: []string{}, wantCycle: true},
+		{name: "missing parent", deptId: "orphan", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Ancestors(dir, tt.deptId)
+			checkErr(t, err, tt.wantCycle)
+			if ids := departmentIds(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if _, err := Ancestors(dir, "nope"); err == nil {
+		t.Error("unknown department: got no error")
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		deptId    string
+		want      []string
+		wantCycle bool
+	}{
+		{name: "whole tree", deptId: "root", want: []string{"eng", "web"}},
+		{name: "leaf", deptId: "web", want: []string{}},
+		{name: "parent cycle", deptId: "loopA", want: []string{"loopB"}, wantCycle: true},
+		{name: "self-reference", deptId: "self", want: []string{}, wantCycle: true},
+		{name: "missing parent", deptId: "orphan", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Descendants(dir, tt.deptId)
+			checkErr(t, err, tt.wantCycle)
+			if ids := departmentIds(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		personId  string
+		want      string
+		wantCycle bool
+	}{
+		{name: "member", personId: "cem", want: "can"},
+		{name: "manager reports to parent manager", personId: "can", want: "ayse"},
+		{name: "top of the hierarchy", personId: "ali", want: ""},
+		{name: "manager cycle", personId: "hakan", want: "irem"},
+		{name: "parent cycle", personId: "deniz", want: "ece"},
+		{name: "self-reference", personId: "fatma", wantCycle: true},
+		{name: "missing parent", personId: "gul", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Manager(dir, tt.personId)
+			checkErr(t, err, tt.wantCycle)
+			id := ""
+			if got != nil {
+				id = got.Id
+			}
+			if id != tt.want {
+				t.Errorf("got %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerChain(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		personId  string
+		want      []string
+		wantCycle bool
+	}{
+		{name: "up to the top", personId: "cem", want: []string{"can", "ayse", "ali"}},
+		{name: "top of the hierarchy", personId: "ali", want: []string{}},
+		{name: "manager cycle", personId: "hakan", want: []string{"irem"}, wantCycle: true},
+		{name: "parent cycle", personId: "deniz", want: []string{"ece"}, wantCycle: true},
+		{name: "self-reference", personId: "fatma", want: []string{}, wantCycle: true},
+		{name: "missing parent", personId: "gul", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ManagerChain(dir, tt.personId)
+			checkErr(t, err, tt.wantCycle)
+			if ids := personIds(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommonAncestor(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		a, b      string
+		want      string
+		wantCycle bool
+	}{
+		{name: "one contains the other", a: "web", b: "root", want: "root"},
+		{name: "same department", a: "eng", b: "eng", want: "eng"},
+		{name: "separate trees", a: "web", b: "orphan", want: ""},
+		{name: "parent cycle", a: "loopA", b: "root", wantCycle: true},
+		{name: "self-reference", a: "root", b: "self", wantCycle: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CommonAncestor(dir, tt.a, tt.b)
+			checkErr(t, err, tt.wantCycle)
+			id := ""
+			if got != nil {
+				id = got.Id
+			}
+			if id != tt.want {
+				t.Errorf("got %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubordinates(t *testing.T) {
+	dir := hierarchyFixture()
+	tests := []struct {
+		name      string
+		personId  string
+		recursive bool
+		want      []string
+	}{
+		{name: "direct reports", personId: "ali", want: []string{"ayse"}},
+		{name: "recursive", personId: "ali", recursive: true, want: []string{"ayse", "burak", "can", "cem"}},
+		{name: "no reports", personId: "cem", recursive: true, want: []string{}},
+		{name: "manager cycle", personId: "hakan", recursive: true, want: []string{"irem"}},
+		{name: "parent cycle", personId: "deniz", recursive: true, want: []string{"ece"}},
+		{name: "self-reference", personId: "fatma", recursive: true, want: []string{}},
+		{name: "missing parent", personId: "gul", recursive: true, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Subordinates(dir, tt.personId, tt.recursive)
+			checkErr(t, err, false)
+			if ids := personIds(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ImportAction is what an import does with a record
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+	ImportConflict  ImportAction = "conflict"
+	ImportReject    ImportAction = "reject"
+)
+
+// ImportItem is a person read from an import source
+type ImportItem struct {
+	// Source tells where the record came from, e.g. "card 3" or "line 12"
+	Source   string
+	Person   Person
+	Warnings []string
+	// Fields lists the fields the source provides, using the JSON field names
+	// and contact kinds. An update keeps the other fields of the existing
+	// person; nil means the source provides every field.
+	Fields []string
+	// Err is set when the record could not be read; it is rejected
+	Err error
+}
+
+// ImportResult is the planned outcome for one ImportItem
+type ImportResult struct {
+	ImportItem
+	Action ImportAction
+	// Existing is the matching person already in the directory
+	Existing *Person
+	// Changes lists the fields that differ from Existing
+	Changes []FieldChange
+	// Reason explains a conflict or rejection
+	Reason string
+}
+
+// PlanImport matches each item against the directory, by Id and then by full
+// name, and decides what to do with it. Items matching an existing person
+// with different fields are conflicts unless overwrite is set, in which case
+// they update that person. newDepartments are departments the import creates,
+// which items may refer to. Nothing is written; see ApplyImport.
+func PlanImport(dir Directory, items []ImportItem, newDepartments []Department, overwrite bool) ([]ImportResult, error) {
+	people, err := dir.GetPeople()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load people: %w", err)
+	}
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+	departments = append(departments, newDepartments...)
+
+	byId := make(map[string]int, len(people))
+	byName := make(map[string]int, len(people))
+	for i, person := range people {
+		if _, ok := byId[person.Id]; !ok {
+			byId[person.Id] = i
+		}
+		name := NormalizeSearch(person.FullName())
+		if _, ok := byName[name]; !ok {
+			byName[name] = i
+		}
+	}
+
+	results := make([]ImportResult, 0, len(items))
+	seen := make(map[string]string)
+	for _, item := range items {
+		result := ImportResult{ImportItem: item}
+		results = append(results, result)
+		r := &results[len(results)-1]
+
+		if item.Err != nil {
+			r.Action, r.Reason = ImportReject, item.Err.Error()
+			continue
+		}
+
+		// Match by Id first; a record without one is matched by name
+		existing := -1
+		if i, ok := byId[item.Person.Id]; ok && item.Person.Id != "" {
+			existing = i
+		} else if i, ok := byName[NormalizeSearch(item.Person.FullName())]; ok {
+			if item.Person.Id != "" {
+				r.Action, r.Existing = ImportConflict, &people[i]
+				r.Reason = fmt.Sprintf("%s already exists with Id %s", people[i].FullName(), people[i].Id)
+				continue
+			}
+			existing = i
+			r.Person.Id = people[i].Id
+		}
+		if existing >= 0 && item.Fields != nil {
+			r.Person = mergePerson(people[existing], r.Person, item.Fields)
+		}
+
+		key := r.Person.Id
+		if key == "" {
+			key = NormalizeSearch(r.Person.FullName())
+		}
+		if source, ok := seen[key]; ok {
+			r.Action, r.Reason = ImportReject, fmt.Sprintf("same person as %s", source)
+			continue
+		}
+		seen[key] = item.Source
+
+		// Validation needs an Id; new people get theirs when created
+		check := r.Person
+		if check.Id == "" {
+			check.Id = "new"
+		}
+		if err := validatePerson(check, departments); err != nil {
+			r.Action, r.Reason = ImportReject, strings.TrimPrefix(err.Error(), "person new: ")
+			continue
+		}
+
+		switch {
+		case existing < 0:
+			r.Action = ImportCreate
+		default:
+			r.Existing = &people[existing]
+			r.Changes = DiffPerson(people[existing], r.Person)
+			switch {
+			case len(r.Changes) == 0:
+				r.Action = ImportUnchanged
+			case overwrite:
+				r.Action = ImportUpdate
+			default:
+				r.Action, r.Reason = ImportConflict, "differs from the existing record"
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// mergePerson returns existing with the given fields taken from imported.
+// A contact kind replaces the existing contacts of that kind.
+func mergePerson(existing, imported Person, fields []string) Person {
+	merged := existing
+	merged.Contacts = slices.Clone(existing.Contacts)
+	for _, field := range fields {
+		switch field {
+		case "prefix":
+			merged.Prefix = imported.Prefix
+		case "firstName":
+			merged.FirstName = imported.FirstName
+		case "lastName":
+			merged.LastName = imported.LastName
+		case "title":
+			merged.Title = imported.Title
+		case "departmentId":
+			merged.DepartmentId = imported.DepartmentId
+		case "room":
+			merged.Room = imported.Room
+		case "floor":
+			merged.Floor = imported.Floor
+		case "phone":
+			merged.Phone = imported.Phone
+		case "contacts":
+			merged.Contacts = slices.Clone(imported.Contacts)
+		default:
+			kind := ContactKind(field)
+			if !slices.Contains(ContactKinds, kind) {
+				continue
+			}
+			var added []ContactPoint
+			for _, c := range imported.Contacts {
+				if c.Kind == kind {
+					added = append(added, c)
+				}
+			}
+			// Existing contacts that are still listed keep their place, label and primary flag
+			merged.Contacts = slices.DeleteFunc(merged.Contacts, func(e ContactPoint) bool {
+				if e.Kind != kind {
+					return false
+				}
+				i := slices.IndexFunc(added, func(c ContactPoint) bool {
+					c.Label, c.Primary = e.Label, e.Primary
+					return sameContact(e, c)
+				})
+				if i < 0 {
+					return true
+				}
+				added = slices.Delete(added, i, i+1)
+				return false
+			})
+			merged.Contacts = append(merged.Contacts, added...)
+		}
+	}
+	if len(merged.Contacts) == 0 {
+		merged.Contacts = nil
+	}
+	return merged
+}
+
+// ApplyImport creates the new departments that planned people refer to and
+// creates and updates the people planned by PlanImport in one write, leaving
+// the directory unchanged when any of them is invalid
+func ApplyImport(editor Editor, newDepartments []Department, results []ImportResult) error {
+	var people []Person
+	for _, result := range results {
+		if result.Action == ImportCreate || result.Action == ImportUpdate {
+			people = append(people, result.Person)
+		}
+	}
+	if err := editor.ApplyChanges(UsedDepartments(newDepartments, results), people); err != nil {
+		return fmt.Errorf("failed to apply import: %w", err)
+	}
+	return nil
+}
+
+// UsedDepartments returns the new departments that people created or updated by results refer to
+func UsedDepartments(newDepartments []Department, results []ImportResult) []Department {
+	used := make(map[string]bool)
+	for _, result := range results {
+		if result.Action == ImportCreate || result.Action == ImportUpdate {
+			used[result.Person.DepartmentId] = true
+		}
+	}
+	var departments []Department
+	for _, dept := range newDepartments {
+		if used[dept.Id] {
+			departments = append(departments, dept)
+		}
+	}
+	return departments
+}
+
+// ImportCounts returns how many results there are per action
+func ImportCounts(results []ImportResult) map[ImportAction]int {
+	counts := make(map[ImportAction]int)
+	for _, result := range results {
+		counts[result.Action]++
+	}
+	return counts
+}
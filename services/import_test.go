@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePerson(t *testing.T) {
+	existing := Person{
+		Id: "ayse", FirstName: "Ayşe", LastName: "Demir", Title: "Engineer", Phone: "+90-212-555-1002",
+		Contacts: []ContactPoint{
+			{Kind: ContactOffice, Value: "+90-212-555-2002", Label: "lab"},
+			{Kind: ContactMobile, Value: "+90-532-555-1002", Primary: true},
+			{Kind: ContactEmail, Value: "ayse@example.com"},
+		},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		imported Person
+		fields   []string
+		want     func(p *Person)
+	}{
+		{
+			name:     "field",
+			imported: Person{FirstName: "Ignored", Title: "Manager"},
+			fields:   []string{"title"},
+			want:     func(p *Person) { p.Title = "Manager" },
+		},
+		{
+			name: "kind keeps listed contacts and adds the others",
+			imported: Person{Contacts: []ContactPoint{
+				{Kind: ContactMobile, Value: "0532 555 10 02"},
+				{Kind: ContactMobile, Value: "+90-533-555-1002"},
+			}},
+			fields: []string{"mobile"},
+			want: func(p *Person) {
+				p.Contacts = append(p.Contacts, ContactPoint{Kind: ContactMobile, Value: "+90-533-555-1002"})
+			},
+		},
+		{
+			name:     "kind removes contacts no longer listed",
+			imported: Person{Contacts: []ContactPoint{{Kind: ContactOffice, Value: "+90-212-555-9999"}}},
+			fields:   []string{"email", "office"},
+			want: func(p *Person) {
+				p.Contacts = []ContactPoint{
+					{Kind: ContactMobile, Value: "+90-532-555-1002", Primary: true},
+					{Kind: ContactOffice, Value: "+90-212-555-9999"},
+				}
+			},
+		},
+		{
+			name:     "kind not mapped is left alone",
+			imported: Person{Contacts: []ContactPoint{{Kind: ContactFax, Value: "+90-212-555-3002"}, {Kind: ContactEmail, Value: "new@example.com"}}},
+			fields:   []string{"fax"},
+			want: func(p *Person) {
+				p.Contacts = append(p.Contacts, ContactPoint{Kind: ContactFax, Value: "+90-212-555-3002"})
+			},
+		},
+		{
+			name:     "contacts replaces every kind",
+			imported: Person{Contacts: []ContactPoint{{Kind: ContactEmail, Value: "new@example.com"}}},
+			fields:   []string{"contacts"},
+			want:     func(p *Person) { p.Contacts = []ContactPoint{{Kind: ContactEmail, Value: "new@example.com"}} },
+		},
+		{
+			name:     "no contacts left",
+			imported: Person{},
+			fields:   []string{"office", "mobile", "email"},
+			want:     func(p *Person) { p.Contacts = nil },
+		},
+		{
+			name:     "unknown field",
+			imported: Person{Title: "Manager"},
+			fields:   []string{"nickname"},
+			want:     func(p *Person) {},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			want := existing
+			want.Contacts = append([]ContactPoint(nil), existing.Contacts...)
+			tt.want(&want)
+
+			got := mergePerson(existing, tt.imported, tt.fields)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePerson = %+v\nwant %+v", got, want)
+			}
+			if len(existing.Contacts) != 3 || existing.Contacts[0].Label != "lab" {
+				t.Errorf("existing person was changed: %+v", existing.Contacts)
+			}
+		})
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	store := NewStore(
+		[]Person{
+			{Id: "ali", FirstName: "Ali", LastName: "Yılmaz", DepartmentId: "eng"},
+			{Id: "ayse", FirstName: "Ayşe", LastName: "Demir", DepartmentId: "eng", Title: "Engineer"},
+		},
+		[]Department{{Id: "eng", Name: "Engineering", ManagerId: "ali"}},
+	)
+	newDepartments := []Department{{Id: "new-dept", Name: "New Department"}}
+
+	items := []ImportItem{
+		{Source: "ali by Id", Person: Person{Id: "ali", FirstName: "Ali", LastName: "Yılmaz", DepartmentId: "eng"}},
+		{Source: "ayse under another Id", Person: Person{Id: "other", FirstName: "Ayşe", LastName: "Demir", DepartmentId: "eng"}},
+		{Source: "ayse by name", Person: Person{FirstName: "Ayşe", LastName: "Demir", DepartmentId: "eng", Title: "Manager"}},
+		{Source: "ali again", Person: Person{FirstName: "ALİ", LastName: "YILMAZ", DepartmentId: "eng"}},
+		{Source: "new person", Person: Person{FirstName: "Yeni", LastName: "Kişi", DepartmentId: "new-dept"}},
+		{Source: "new person with Id", Person: Person{Id: "can", FirstName: "Can", LastName: "Kaya"}},
+		{Source: "invalid", Person: Person{FirstName: "Eksik"}},
+		{Source: "unreadable", Err: errors.New("floor \"bir\" is not a number")},
+	}
+	want := []struct {
+		action   ImportAction
+		id       string
+		existing string
+		reason   string
+	}{
+		{ImportUnchanged, "ali", "ali", ""},
+		{ImportConflict, "other", "ayse", "Ayşe Demir already exists with Id ayse"},
+		{ImportConflict, "ayse", "ayse", "differs from the existing record"},
+		{ImportReject, "ali", "", "same person as ali by Id"},
+		{ImportCreate, "", "", ""},
+		{ImportCreate, "can", "", ""},
+		{ImportReject, "", "", "last name is required"},
+		{ImportReject, "", "", `floor "bir" is not a number`},
+	}
+
+	results, err := PlanImport(store, items, newDepartments, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		existing := ""
+		if r.Existing != nil {
+			existing = r.Existing.Id
+		}
+		if r.Action != want[i].action || r.Person.Id != want[i].id || existing != want[i].existing || r.Reason != want[i].reason {
+			t.Errorf("%s: got %s of %q matching %q (%q), want %s of %q matching %q (%q)",
+				r.Source, r.Action, r.Person.Id, existing, r.Reason, want[i].action, want[i].id, want[i].existing, want[i].reason)
+		}
+	}
+	if changes := results[2].Changes; len(changes) != 1 || changes[0].Field != "title" {
+		t.Errorf("changes of ayse by name are %+v, want the title", changes)
+	}
+
+	// With overwrite the differing match is an update; an Id conflict stays a conflict
+	results, err = PlanImport(store, items[1:3], nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Action != ImportConflict || results[1].Action != ImportUpdate {
+		t.Errorf("with overwrite the actions are %s and %s, want conflict and update", results[0].Action, results[1].Action)
+	}
+}
+
+func TestPlanImportMergesMappedFields(t *testing.T) {
+	store := NewStore(
+		[]Person{{Id: "ayse", FirstName: "Ayşe", LastName: "Demir", Room: "A-205", Title: "Engineer"}},
+		nil,
+	)
+	// A source with only names and a title must not clear the room
+	items := []ImportItem{{
+		Source: "line 2",
+		Person: Person{FirstName: "Ayşe", LastName: "Demir", Title: "Manager"},
+		Fields: []string{"firstName", "lastName", "title"},
+	}}
+
+	results, err := PlanImport(store, items, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := results[0]
+	if r.Action != ImportUpdate || r.Person.Id != "ayse" || r.Person.Room != "A-205" || r.Person.Title != "Manager" {
+		t.Errorf("result is %s of %+v, want an update of the title only", r.Action, r.Person)
+	}
+}
@@ -11,6 +11,8 @@ type Person struct {
 	Floor        int     `json:"floor"`
 	DepartmentId string  `json:"departmentId"`
 	Title        string  `json:"title"`
+	// Contacts lists further channels besides Phone, see ContactPoints
+	Contacts []ContactPoint `json:"contacts,omitempty"`
 }
 
 // Department represents a department in the system
@@ -21,3 +23,22 @@ type Department struct {
 	ManagerId          string  `json:"managerId"`
 	ParentDepartmentId *string `json:"parentDepartmentId"`
 }
+
+// FullName returns the first and last name
+func (p Person) FullName() string {
+	return p.FirstName + " " + p.LastName
+}
+
+// DisplayName returns the full name preceded by the prefix, if any
+func (p Person) DisplayName() string {
+	if p.Prefix != nil && *p.Prefix != "" {
+		return *p.Prefix + " " + p.FullName()
+	}
+	return p.FullName()
+}
+
+// HasParent reports whether the department names a parent department; an
+// empty ParentDepartmentId is the same as none
+func (d Department) HasParent() bool {
+	return d.ParentDepartmentId != nil && *d.ParentDepartmentId != ""
+}
@@ -4,32 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
 // GetPeople reads and returns all people from the JSON file
-func GetPeople() ([]Person, error) {
-	// Get the path to the data directory relative to the project root
-	dataPath := filepath.Join("data", "people.json")
-
+func (d *JSONDirectory) GetPeople() ([]Person, error) {
 	// Read the file
-	data, err := os.ReadFile(dataPath)
+	data, err := os.ReadFile(d.PeoplePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read people.json: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", d.PeoplePath, err)
 	}
 
 	// Unmarshal JSON data
 	var people []Person
 	if err := json.Unmarshal(data, &people); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal people.json: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", d.PeoplePath, err)
 	}
 
 	return people, nil
 }
 
 // GetPersonById finds a person by their Id
-func GetPersonById(id string) (*Person, error) {
-	people, err := GetPeople()
+func (d *JSONDirectory) GetPersonById(id string) (*Person, error) {
+	people, err := d.GetPeople()
 	if err != nil {
 		return nil, err
 	}
@@ -44,8 +40,8 @@ func GetPersonById(id string) (*Person, error) {
 }
 
 // GetPeopleByDepartmentId returns all people in a specific department
-func GetPeopleByDepartmentId(departmentId string) ([]Person, error) {
-	people, err := GetPeople()
+func (d *JSONDirectory) GetPeopleByDepartmentId(departmentId string) ([]Person, error) {
+	people, err := d.GetPeople()
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +55,103 @@ func GetPeopleByDepartmentId(departmentId string) ([]Person, error) {
 
 	return result, nil
 }
+
+// CreatePerson validates a new person and appends it to the JSON file
+func (d *JSONDirectory) CreatePerson(person Person) (*Person, error) {
+	people, departments, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if person.Id == "" {
+		person.Id = newId()
+	} else if findPerson(people, person.Id) >= 0 {
+		return nil, fmt.Errorf("person with Id %s already exists", person.Id)
+	}
+	if err := validatePerson(person, departments); err != nil {
+		return nil, err
+	}
+
+	if err := writeJSONAtomic(d.PeoplePath, append(people, person)); err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// UpdatePerson validates and replaces the person with the same Id in the JSON file
+func (d *JSONDirectory) UpdatePerson(person Person) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	i := findPerson(people, person.Id)
+	if i < 0 {
+		return fmt.Errorf("person with Id %s not found", person.Id)
+	}
+	if err := validatePerson(person, departments); err != nil {
+		return err
+	}
+
+	people[i] = person
+	return writeJSONAtomic(d.PeoplePath, people)
+}
+
+// DeletePerson removes a person from the JSON file
+func (d *JSONDirectory) DeletePerson(id string) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	i := findPerson(people, id)
+	if i < 0 {
+		return fmt.Errorf("person with Id %s not found", id)
+	}
+	if err := checkPersonDeletable(id, departments); err != nil {
+		return err
+	}
+
+	return writeJSONAtomic(d.PeoplePath, append(people[:i], people[i+1:]...))
+}
+
+// ApplyChanges validates departments and people against the merged data and
+// rewrites each data file once
+func (d *JSONDirectory) ApplyChanges(departments []Department, people []Person) error {
+	departments, people = assignIds(departments, people)
+	current, currentDepartments, err := d.load()
+	if err != nil {
+		return err
+	}
+	merged, mergedDepartments, err := mergeChanges(current, currentDepartments, departments, people)
+	if err != nil {
+		return err
+	}
+
+	// Departments are written first so people never refer to a missing one
+	if len(departments) > 0 {
+		if err := writeJSONAtomic(d.DepartmentsPath, mergedDepartments); err != nil {
+			return err
+		}
+	}
+	if len(people) > 0 {
+		if err := writeJSONAtomic(d.PeoplePath, merged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load reads both data files for validating a change
+func (d *JSONDirectory) load() ([]Person, []Department, error) {
+	people, err := d.GetPeople()
+	if err != nil {
+		return nil, nil, err
+	}
+	departments, err := d.GetDepartments()
+	if err != nil {
+		return nil, nil, err
+	}
+	return people, departments, nil
+}
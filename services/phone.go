@@ -0,0 +1,335 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultCountryCode is the calling code assumed for numbers written without one
+const defaultCountryCode = "90"
+
+// minExtensionDigits is the shortest number matched against the end of phone numbers
+const minExtensionDigits = 3
+
+// maxExtensionDigits is the longest number treated as an internal extension on its own
+const maxExtensionDigits = 6
+
+// internalExtensionDigits is how many trailing subscriber digits are dialled
+// internally when a number has no explicit extension
+const internalExtensionDigits = 4
+
+// maxE164Digits is the longest number allowed by E.164, country code included
+const maxE164Digits = 15
+
+// PhoneNumber is a phone number split into its parts. Numbers in the default
+// country are split into a 3-digit area code and a 7-digit subscriber number;
+// for other countries the whole national number is the subscriber number.
+// A bare internal extension only has Extension set.
+type PhoneNumber struct {
+	CountryCode string `json:"countryCode,omitempty" yaml:"countryCode,omitempty"`
+	AreaCode    string `json:"areaCode,omitempty" yaml:"areaCode,omitempty"`
+	Subscriber  string `json:"subscriber,omitempty" yaml:"subscriber,omitempty"`
+	// Extension is the internal extension written after the number, e.g. "x12" or "dahili 12"
+	Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+}
+
+// extensionPattern matches an extension written after the number
+var extensionPattern = regexp.MustCompile(`(?i)\s*(?:;\s*ext=|#|x|extension|ext\.?|dahili)\s*(\d+)\s*$`)
+
+// ParsePhone parses a free-form phone number such as "+90-212-555-1001",
+// "0212 555 10 01", "+44 20 7946 0958", "+90 212 555 1000 x12" or "1001"
+func ParsePhone(s string) (PhoneNumber, error) {
+	var p PhoneNumber
+	number := strings.TrimSpace(s)
+	if number == "" {
+		return p, fmt.Errorf("phone number is empty")
+	}
+
+	// Extensions are marked with letters, # or ;, so numbers without them skip the pattern
+	if strings.IndexFunc(number, isNotPhoneRune) >= 0 {
+		if m := extensionPattern.FindStringSubmatchIndex(number); m != nil {
+			p.Extension = number[m[2]:m[3]]
+			number = strings.TrimSpace(number[:m[0]])
+		}
+	}
+
+	// Only digits and the usual punctuation are allowed, with + leading
+	if i := strings.IndexFunc(number, isNotPhoneRune); i >= 0 {
+		return p, fmt.Errorf("phone number %q contains %q", s, number[i:i+1])
+	}
+	if strings.LastIndex(number, "+") > 0 {
+		return p, fmt.Errorf("phone number %q has + in the middle", s)
+	}
+
+	digits := phoneDigits(number)
+	international := strings.HasPrefix(number, "+")
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+
+	var national string
+	switch {
+	case international:
+		p.CountryCode = countryCode(digits)
+		if p.CountryCode == "" {
+			return p, fmt.Errorf("phone number %q has no country code", s)
+		}
+		national = digits[len(p.CountryCode):]
+	case len(digits) <= maxExtensionDigits && p.Extension == "":
+		if len(digits) < minExtensionDigits {
+			return p, fmt.Errorf("phone number %q is too short", s)
+		}
+		p.Extension = digits
+		return p, nil
+	case len(digits) == 11 && strings.HasPrefix(digits, "0"):
+		p.CountryCode, national = defaultCountryCode, digits[1:]
+	case len(digits) == 12 && strings.HasPrefix(digits, defaultCountryCode):
+		p.CountryCode, national = defaultCountryCode, digits[len(defaultCountryCode):]
+	case len(digits) == 10:
+		p.CountryCode, national = defaultCountryCode, digits
+	default:
+		return p, fmt.Errorf("phone number %q is not a full number or an extension", s)
+	}
+
+	if len(p.CountryCode)+len(national) > maxE164Digits {
+		return p, fmt.Errorf("phone number %q is longer than %d digits", s, maxE164Digits)
+	}
+	if p.CountryCode == defaultCountryCode {
+		if len(national) != 10 {
+			return p, fmt.Errorf("phone number %q should have 10 digits after +%s", s, defaultCountryCode)
+		}
+		p.AreaCode, p.Subscriber = national[:3], national[3:]
+	} else {
+		if national == "" {
+			return p, fmt.Errorf("phone number %q has no subscriber number", s)
+		}
+		p.Subscriber = national
+	}
+	return p, nil
+}
+
+// isNotPhoneRune reports whether r is neither a digit nor punctuation used in phone numbers
+func isNotPhoneRune(r rune) bool {
+	return !(r >= '0' && r <= '9' || strings.ContainsRune("+-.()/ ", r))
+}
+
+// twoDigitCountryCodes lists the calling codes of two digits. Codes are
+// prefix-free: 1 and 7 take one digit, these two, and the rest three.
+var twoDigitCountryCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true, "36": true,
+	"39": true, "40": true, "41": true, "43": true, "44": true, "45": true, "46": true, "47": true,
+	"48": true, "49": true, "51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true, "64": true, "65": true,
+	"66": true, "81": true, "82": true, "84": true, "86": true, "90": true, "91": true, "92": true,
+	"93": true, "94": true, "95": true, "98": true,
+}
+
+// countryCode returns the calling code at the start of digits
+func countryCode(digits string) string {
+	switch {
+	case len(digits) < 4:
+		return ""
+	case digits[0] == '1' || digits[0] == '7':
+		return digits[:1]
+	case twoDigitCountryCodes[digits[:2]]:
+		return digits[:2]
+	}
+	return digits[:3]
+}
+
+// IsExtension reports whether the number is only an internal extension
+func (p PhoneNumber) IsExtension() bool {
+	return p.Subscriber == ""
+}
+
+// National returns the number without the country code, e.g. 2125551001
+func (p PhoneNumber) National() string {
+	if p.IsExtension() {
+		return p.Extension
+	}
+	return p.AreaCode + p.Subscriber
+}
+
+// E164 returns the number in E.164 form, e.g. +902125551001, or "" for a bare extension
+func (p PhoneNumber) E164() string {
+	if p.IsExtension() || p.CountryCode == "" {
+		return ""
+	}
+	return "+" + p.CountryCode + p.National()
+}
+
+// InternalExtension returns the explicit extension, or the last digits of
+// the subscriber number that are dialled internally
+func (p PhoneNumber) InternalExtension() string {
+	if p.Extension != "" {
+		return p.Extension
+	}
+	if len(p.Subscriber) > internalExtensionDigits {
+		return p.Subscriber[len(p.Subscriber)-internalExtensionDigits:]
+	}
+	return p.Subscriber
+}
+
+// PhoneStyle selects how phone numbers are displayed
+type PhoneStyle string
+
+const (
+	// PhoneAsEntered shows numbers as they are written in the data
+	PhoneAsEntered     PhoneStyle = ""
+	PhoneInternational PhoneStyle = "international"
+	PhoneNational      PhoneStyle = "national"
+	PhoneExtension     PhoneStyle = "extension"
+	PhoneE164          PhoneStyle = "e164"
+)
+
+// PhoneStyles lists every display style
+var PhoneStyles = []PhoneStyle{PhoneAsEntered, PhoneInternational, PhoneNational, PhoneExtension, PhoneE164}
+
+// ParsePhoneStyle validates a display style name; "raw" is the same as an empty name
+func ParsePhoneStyle(name string) (PhoneStyle, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "raw" {
+		return PhoneAsEntered, nil
+	}
+	for _, style := range PhoneStyles {
+		if string(style) == name {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("unknown phone style %q, expected raw, international, national, extension or e164", name)
+}
+
+// Format renders the number in a display style:
+//
+//	international  +90 212 555 1001
+//	national       0212 555 1001
+//	extension      1001
+//	e164           +902125551001
+//
+// An explicit extension is appended as " x12" except in the extension style.
+func (p PhoneNumber) Format(style PhoneStyle) string {
+	if p.IsExtension() || style == PhoneExtension {
+		return p.InternalExtension()
+	}
+
+	var number string
+	switch {
+	case style == PhoneE164:
+		number = p.E164()
+	case p.CountryCode == defaultCountryCode && style == PhoneNational:
+		number = "0" + p.AreaCode + " " + p.Subscriber[:3] + " " + p.Subscriber[3:]
+	case p.CountryCode == defaultCountryCode:
+		number = "+" + p.CountryCode + " " + p.AreaCode + " " + p.Subscriber[:3] + " " + p.Subscriber[3:]
+	case p.CountryCode != "":
+		number = "+" + p.CountryCode + " " + p.Subscriber
+	default:
+		number = p.Subscriber
+	}
+	if p.Extension != "" {
+		number += " x" + p.Extension
+	}
+	return number
+}
+
+// FormatPhone renders a phone number in a display style, leaving numbers that
+// cannot be parsed as they are
+func FormatPhone(phone string, style PhoneStyle) string {
+	if style == PhoneAsEntered {
+		return phone
+	}
+	p, err := ParsePhone(phone)
+	if err != nil {
+		return phone
+	}
+	return p.Format(style)
+}
+
+// PhoneNumber parses the person's phone number
+func (p Person) PhoneNumber() (PhoneNumber, error) {
+	return ParsePhone(p.Phone)
+}
+
+// FormattedPhone returns the person's phone number in a display style
+func (p Person) FormattedPhone(style PhoneStyle) string {
+	return FormatPhone(p.Phone, style)
+}
+
+// PhoneNumber parses the department's phone number
+func (d Department) PhoneNumber() (PhoneNumber, error) {
+	return ParsePhone(d.Phone)
+}
+
+// FormattedPhone returns the department's phone number in a display style
+func (d Department) FormattedPhone(style PhoneStyle) string {
+	return FormatPhone(d.Phone, style)
+}
+
+// NationalNumber reduces a phone number to its national digits, dropping
+// spaces, punctuation, the +90 or 0090 country code and the trunk 0, so
+// "+90-212-555-1002", "0212 555 10 02" and "2125551002" are all "2125551002".
+// Numbers that cannot be parsed are returned as digits only.
+func NationalNumber(phone string) string {
+	p, err := ParsePhone(phone)
+	if err != nil {
+		return phoneDigits(phone)
+	}
+	if p.CountryCode != "" && p.CountryCode != defaultCountryCode {
+		return p.CountryCode + p.National()
+	}
+	return p.National()
+}
+
+// LookupPhone finds the people and departments owning a phone number. A full
+// number must match exactly; a shorter number, such as a 4-digit extension,
+// matches the numbers ending in it and explicit extensions.
+func LookupPhone(dir Directory, number string) ([]Person, []Department, error) {
+	query := NationalNumber(number)
+	if len(query) < minExtensionDigits {
+		return nil, nil, fmt.Errorf("phone number %q is too short, expected at least %d digits", number, minExtensionDigits)
+	}
+
+	matches := func(phone string) bool {
+		national := NationalNumber(phone)
+		if national == "" {
+			return false
+		}
+		if p, err := ParsePhone(phone); err == nil && p.Extension == query {
+			return true
+		}
+		if len(query) >= len(national) {
+			return query == national
+		}
+		return strings.HasSuffix(national, query)
+	}
+
+	people, err := dir.GetPeople()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load people: %w", err)
+	}
+	departments, err := dir.GetDepartments()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+
+	var foundPeople []Person
+	for _, person := range people {
+		if slices.ContainsFunc(person.ContactPoints(), func(c ContactPoint) bool {
+			return c.Kind.IsPhone() && matches(c.Value)
+		}) {
+			foundPeople = append(foundPeople, person)
+		}
+	}
+	var foundDepartments []Department
+	for _, dept := range departments {
+		if matches(dept.Phone) {
+			foundDepartments = append(foundDepartments, dept)
+		}
+	}
+	SortPeople(foundPeople)
+	SortDepartments(foundDepartments)
+
+	return foundPeople, foundDepartments, nil
+}
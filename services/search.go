@@ -0,0 +1,489 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SearchScope limits which kinds of records a search returns
+type SearchScope int
+
+const (
+	ScopeAll SearchScope = iota
+	ScopePeople
+	ScopeDepartments
+)
+
+// SearchOptions configures Search
+type SearchOptions struct {
+	// Scope selects people, departments or both
+	Scope SearchScope
+	// Limit caps the number of results; zero means no limit
+	Limit int
+	// DepartmentId keeps only the department and its sub-departments,
+	// recursively, and their members. The hierarchy is resolved by Search;
+	// SearchPeople and SearchDepartments on their own match the department only.
+	DepartmentId string
+	// Floor keeps only people on the given floor and excludes departments
+	Floor *int
+
+	// subtree holds the Ids of DepartmentId and its descendants, set by Search
+	subtree map[string]bool
+}
+
+// inDepartment reports whether a record in the department deptId passes the DepartmentId filter
+func (o SearchOptions) inDepartment(deptId string) bool {
+	switch {
+	case o.DepartmentId == "":
+		return true
+	case o.subtree != nil:
+		return o.subtree[deptId]
+	}
+	return deptId == o.DepartmentId
+}
+
+// Span is a matched range of rune offsets [Start, End) within a field value
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// FieldMatch records which parts of a field matched the query
+type FieldMatch struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	Spans []Span `json:"spans"`
+}
+
+// SearchResult is a person or department matching a query
+type SearchResult struct {
+	Kind       string       `json:"kind"`
+	Person     *Person      `json:"person,omitempty"`
+	Department *Department  `json:"department,omitempty"`
+	Score      int          `json:"score"`
+	Matches    []FieldMatch `json:"matches"`
+}
+
+// Name returns the display name of the matched record
+func (r SearchResult) Name() string {
+	if r.Person != nil {
+		return r.Person.DisplayName()
+	}
+	return r.Department.Name
+}
+
+// Spans returns the matched spans of field, or nil when it did not match
+func (r SearchResult) Spans(field string) []Span {
+	for _, match := range r.Matches {
+		if match.Field == field {
+			return match.Spans
+		}
+	}
+	return nil
+}
+
+// Match scores, from strongest to weakest
+const (
+	scorePhoneSuffix = 150
+	scoreExact       = 100
+	scorePrefix      = 80
+	scoreSubstring   = 60
+	scoreTypo        = 50
+	scoreTypoStep    = 15
+	scoreSubsequence = 25
+	boostLastName    = 60
+)
+
+// Search ranks people and departments in dir against query. Every whitespace
+// separated term must match some field; terms match exactly, as a prefix or
+// substring, with a few typos, or as an in-order subsequence of a word. Exact
+// last-name matches and phone numbers ending in the query are boosted.
+func Search(dir Directory, query string, opts SearchOptions) ([]SearchResult, error) {
+	// Limit applies to the combined results only
+	filters := opts
+	filters.Limit = 0
+	if opts.DepartmentId != "" {
+		// A cycle below the department still leaves the part that was reached
+		descendants, err := Descendants(dir, opts.DepartmentId)
+		if err != nil && !errors.As(err, new(*CycleError)) {
+			return nil, err
+		}
+		filters.subtree = map[string]bool{opts.DepartmentId: true}
+		for _, dept := range descendants {
+			filters.subtree[dept.Id] = true
+		}
+	}
+
+	var results []SearchResult
+	if opts.Scope != ScopeDepartments {
+		people, err := dir.GetPeople()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchPeople(people, query, filters)...)
+	}
+	if opts.Scope != ScopePeople {
+		departments, err := dir.GetDepartments()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchDepartments(departments, query, filters)...)
+	}
+
+	return rank(results, opts.Limit), nil
+}
+
+// SearchPeople ranks people against query
+func SearchPeople(people []Person, query string, opts SearchOptions) []SearchResult {
+	terms := strings.Fields(NormalizeSearch(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []SearchResult
+	for i := range people {
+		person := people[i]
+		if !opts.inDepartment(person.DepartmentId) {
+			continue
+		}
+		if opts.Floor != nil && person.Floor != *opts.Floor {
+			continue
+		}
+
+		fields := []searchField{
+			{name: "name", value: person.DisplayName(), weight: 10},
+			{name: "title", value: person.Title, weight: 5},
+			{name: "room", value: person.Room, weight: 8},
+			{name: "phone", value: person.Phone, weight: 9, phone: true},
+		}
+		for _, contact := range person.Contacts {
+			fields = append(fields, searchField{name: "contact", value: contact.Value, weight: 7, phone: contact.Kind.IsPhone()})
+		}
+		lastName := NormalizeSearch(person.LastName)
+
+		score, matches, ok := matchFields(fields, terms, func(term string) int {
+			if term == lastName {
+				return boostLastName
+			}
+			return 0
+		})
+		if ok {
+			results = append(results, SearchResult{Kind: EntityPerson, Person: &person, Score: score, Matches: matches})
+		}
+	}
+
+	return rank(results, opts.Limit)
+}
+
+// SearchDepartments ranks departments against query
+func SearchDepartments(departments []Department, query string, opts SearchOptions) []SearchResult {
+	// Departments have no floor, so a floor filter excludes all of them
+	terms := strings.Fields(NormalizeSearch(query))
+	if len(terms) == 0 || opts.Floor != nil {
+		return nil
+	}
+
+	var results []SearchResult
+	for i := range departments {
+		dept := departments[i]
+		if !opts.inDepartment(dept.Id) {
+			continue
+		}
+
+		fields := []searchField{
+			{name: "name", value: dept.Name, weight: 10},
+			{name: "phone", value: dept.Phone, weight: 9, phone: true},
+		}
+
+		score, matches, ok := matchFields(fields, terms, nil)
+		if ok {
+			results = append(results, SearchResult{Kind: EntityDepartment, Department: &dept, Score: score, Matches: matches})
+		}
+	}
+
+	return rank(results, opts.Limit)
+}
+
+// rank sorts results by score, then alphabetically, and applies limit
+func rank(results []SearchResult, limit int) []SearchResult {
+	c := NewCollator()
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		return c.CompareString(a.Name(), b.Name())
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// searchField is a field of a record considered by the search
+type searchField struct {
+	name   string
+	value  string
+	weight int // out of 10
+	phone  bool
+}
+
+// matchFields scores every term against the fields. ok is false when a term matches nothing.
+func matchFields(fields []searchField, terms []string, boost func(term string) int) (score int, matches []FieldMatch, ok bool) {
+	spans := make([][]Span, len(fields))
+
+	for _, term := range terms {
+		best, bestField := 0, -1
+		var bestSpans []Span
+		for i, field := range fields {
+			var s int
+			var sp []Span
+			if field.phone {
+				s, sp = matchPhone(field.value, term)
+			} else {
+				s, sp = matchText(field.value, term)
+			}
+			s = s * field.weight / 10
+			if s > best {
+				best, bestField, bestSpans = s, i, sp
+			}
+		}
+		if bestField < 0 {
+			return 0, nil, false
+		}
+
+		if boost != nil {
+			best += boost(term)
+		}
+		score += best
+		spans[bestField] = append(spans[bestField], bestSpans...)
+	}
+
+	for i, field := range fields {
+		if len(spans[i]) > 0 {
+			matches = append(matches, FieldMatch{Field: field.name, Value: field.value, Spans: mergeSpans(spans[i])})
+		}
+	}
+	return score, matches, true
+}
+
+// word is a run of letters and digits, as rune offsets into a normalized value
+type word struct {
+	start, end int
+}
+
+func words(runes []rune) []word {
+	var result []word
+	start := -1
+	for i, r := range runes {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			result = append(result, word{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{start, len(runes)})
+	}
+	return result
+}
+
+// matchText scores a term against a text value, returning the best match
+func matchText(value, term string) (int, []Span) {
+	text := []rune(NormalizeSearch(value))
+	t := []rune(term)
+	ws := words(text)
+
+	for _, w := range ws {
+		if string(text[w.start:w.end]) == term {
+			return scoreExact, []Span{{w.start, w.end}}
+		}
+	}
+	for _, w := range ws {
+		if strings.HasPrefix(string(text[w.start:w.end]), term) {
+			return scorePrefix, []Span{{w.start, w.start + len(t)}}
+		}
+	}
+	if i := runeIndex(text, t); i >= 0 {
+		return scoreSubstring, []Span{{i, i + len(t)}}
+	}
+
+	// Numbers are never matched approximately
+	if digitsOnly(term) == term {
+		return 0, nil
+	}
+
+	// Typos: compare against whole words and against word prefixes of the
+	// term's length, so partially typed words still match
+	maxTypos := allowedTypos(len(t))
+	if maxTypos > 0 {
+		bestDist, bestWord := maxTypos+1, word{}
+		for _, w := range ws {
+			candidate := text[w.start:w.end]
+			if d := levenshtein(t, candidate, maxTypos); d < bestDist {
+				bestDist, bestWord = d, w
+			}
+			if len(candidate) > len(t) {
+				if d := levenshtein(t, candidate[:len(t)], maxTypos); d < bestDist {
+					bestDist, bestWord = d, word{w.start, w.start + len(t)}
+				}
+			}
+		}
+		if bestDist <= maxTypos {
+			return scoreTypo - scoreTypoStep*(bestDist-1), []Span{{bestWord.start, bestWord.end}}
+		}
+	}
+
+	// Subsequence within a single word starting at its first letter, e.g. "ahmt" → "ahmet"
+	for _, w := range ws {
+		if text[w.start] != t[0] {
+			continue
+		}
+		if spans := subsequence(text[w.start:w.end], t); spans != nil {
+			for i := range spans {
+				spans[i].Start += w.start
+				spans[i].End += w.start
+			}
+			return scoreSubsequence, mergeSpans(spans)
+		}
+	}
+
+	return 0, nil
+}
+
+// matchPhone matches a numeric term against the digits of a phone number
+func matchPhone(value, term string) (int, []Span) {
+	// Only numeric terms, optionally with phone punctuation, are matched against phone numbers
+	termDigits := digitsOnly(term)
+	if termDigits == "" || strings.Trim(term, "0123456789+-()") != "" {
+		return 0, nil
+	}
+	// A full number is matched without its country code or trunk 0, so
+	// 02125551001 finds +90-212-555-1001
+	if p, err := ParsePhone(term); err == nil && !p.IsExtension() {
+		termDigits = p.National()
+	}
+
+	// Keep the rune offset of every digit so matches map back to the formatted value
+	var digits []rune
+	var offsets []int
+	for i, r := range []rune(value) {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+			offsets = append(offsets, i)
+		}
+	}
+
+	td := []rune(termDigits)
+	spanOf := func(start int) []Span {
+		var spans []Span
+		for i := start; i < start+len(td); i++ {
+			spans = append(spans, Span{offsets[i], offsets[i] + 1})
+		}
+		return mergeSpans(spans)
+	}
+
+	if len(td) >= 3 && strings.HasSuffix(string(digits), termDigits) {
+		return scorePhoneSuffix, spanOf(len(digits) - len(td))
+	}
+	if i := runeIndex(digits, td); i >= 0 {
+		return scoreSubstring, spanOf(i)
+	}
+	return 0, nil
+}
+
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// allowedTypos returns how many edits a term of length n may contain
+func allowedTypos(n int) int {
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// levenshtein returns the edit distance between a and b, or max+1 once it exceeds max
+func levenshtein(a, b []rune, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return min(prev[len(b)], max+1)
+}
+
+// subsequence returns single-rune spans for t found in order within text, or nil
+func subsequence(text, t []rune) []Span {
+	var spans []Span
+	j := 0
+	for i := 0; i < len(text) && j < len(t); i++ {
+		if text[i] == t[j] {
+			spans = append(spans, Span{i, i + 1})
+			j++
+		}
+	}
+	if j < len(t) {
+		return nil
+	}
+	return spans
+}
+
+func runeIndex(text, t []rune) int {
+	for i := 0; i+len(t) <= len(text); i++ {
+		if slices.Equal(text[i:i+len(t)], t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeSpans sorts spans and joins overlapping or adjacent ones
+func mergeSpans(spans []Span) []Span {
+	slices.SortFunc(spans, func(a, b Span) int { return a.Start - b.Start })
+
+	var merged []Span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, s.End)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchText(t *testing.T) {
+	for _, tt := range []struct {
+		value, term string
+		score       int
+		spans       []Span
+	}{
+		{"Ayşe Demir", "demir", scoreExact, []Span{{5, 10}}},
+		{"Mustafa Öztürk", "ozturk", scoreExact, []Span{{8, 14}}},
+		{"Ayşe Demir", "dem", scorePrefix, []Span{{5, 8}}},
+		{"Ayşe Demir", "emi", scoreSubstring, []Span{{6, 9}}},
+		{"Ayşe Demir", "demor", scoreTypo, []Span{{5, 10}}},
+		{"Abdurrahman", "abdurahmam", scoreTypo - scoreTypoStep, []Span{{0, 11}}},
+		// A partially typed word with a typo matches the start of the word
+		{"Abdurrahman", "abdr", scoreTypo, []Span{{0, 4}}},
+		{"Abdurrahman", "abdrhmn", scoreSubsequence, []Span{{0, 3}, {4, 5}, {7, 9}, {10, 11}}},
+		{"Ayşe Demir", "dmr", scoreSubsequence, []Span{{5, 6}, {7, 8}, {9, 10}}},
+		// Short terms and numbers allow no typos
+		{"Ayşe Demir", "dex", 0, nil},
+		{"A-205", "206", 0, nil},
+		{"Ayşe Demir", "kaya", 0, nil},
+	} {
+		score, spans := matchText(tt.value, tt.term)
+		if score != tt.score || !slices.Equal(spans, tt.spans) {
+			t.Errorf("matchText(%q, %q) = %d, %v; want %d, %v", tt.value, tt.term, score, spans, tt.score, tt.spans)
+		}
+	}
+}
+
+func TestSearchPeopleRanksMatchKinds(t *testing.T) {
+	people := []Person{
+		{Id: "typo", FirstName: "Deniz", LastName: "Kaba"},
+		{Id: "substring", FirstName: "Cem", LastName: "Özkaya"},
+		{Id: "prefix", FirstName: "Berk", LastName: "Kayacan"},
+		{Id: "exact", FirstName: "Ali", LastName: "Kaya"},
+		{Id: "none", FirstName: "Ece", LastName: "Yılmaz"},
+	}
+
+	for _, query := range []string{"kaya", "KAYA"} {
+		results := SearchPeople(people, query, SearchOptions{})
+		var got []string
+		for i, r := range results {
+			got = append(got, r.Person.Id)
+			if i > 0 && r.Score >= results[i-1].Score {
+				t.Errorf("%q: %s scores %d, not below %s at %d", query, r.Person.Id, r.Score, results[i-1].Person.Id, results[i-1].Score)
+			}
+		}
+		if want := []string{"exact", "prefix", "substring", "typo"}; !slices.Equal(got, want) {
+			t.Errorf("%q: results are %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package servicestest generates directories of any size for tests and benchmarks
+package servicestest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/htekgulds/terminal-rehber/services"
+)
+
+// PeoplePerDepartment is how many people Generate puts in each department
+const PeoplePerDepartment = 100
+
+var (
+	firstNames = []string{"Ahmet", "Ayşe", "Mehmet", "Fatma", "Mustafa", "Zeynep", "Emre", "Elif", "Hakan", "Şule", "İbrahim", "Gül"}
+	lastNames  = []string{"Yılmaz", "Demir", "Kaya", "Şahin", "Çelik", "Öztürk", "Arslan", "Doğan", "Güler", "Aydın", "Özdemir", "Kılıç"}
+	prefixes   = []string{"", "", "Dr.", "Prof.", "Prof. Dr."}
+)
+
+// Generate returns a valid directory of the given number of people, with
+// PeoplePerDepartment people per department. Departments form a tree where
+// each one has up to four sub-departments and is managed by its first member.
+// The result is the same on every call.
+func Generate(people int) ([]services.Person, []services.Department) {
+	deptCount := max(1, (people+PeoplePerDepartment-1)/PeoplePerDepartment)
+
+	departments := make([]services.Department, deptCount)
+	for i := range departments {
+		departments[i] = services.Department{
+			Id:    id(5, i),
+			Name:  fmt.Sprintf("Department %d", i+1),
+			Phone: fmt.Sprintf("+90-312-%03d-%04d", 100+i/10000, i%10000),
+		}
+		if i*PeoplePerDepartment < people {
+			departments[i].ManagerId = id(6, i*PeoplePerDepartment)
+		}
+		if i > 0 {
+			parentId := id(5, (i-1)/4)
+			departments[i].ParentDepartmentId = &parentId
+		}
+	}
+
+	result := make([]services.Person, people)
+	for i := range result {
+		result[i] = services.Person{
+			Id:           id(6, i),
+			FirstName:    firstNames[i%len(firstNames)],
+			LastName:     lastNames[(i/len(firstNames))%len(lastNames)],
+			Room:         fmt.Sprintf("%c-%03d", 'A'+rune(i%6), i%400),
+			Phone:        fmt.Sprintf("+90-212-%03d-%04d", 100+i/10000, i%10000),
+			Floor:        i % 8,
+			DepartmentId: id(5, i/PeoplePerDepartment),
+			Title:        "Engineer",
+		}
+		if prefix := prefixes[i%len(prefixes)]; prefix != "" {
+			result[i].Prefix = &prefix
+		}
+	}
+	return result, departments
+}
+
+// WriteJSON writes a generated directory of the given size as people.json and
+// departments.json inside dir and returns it as a JSONDirectory
+func WriteJSON(dir string, people int) (*services.JSONDirectory, error) {
+	p, d := Generate(people)
+	directory := services.NewJSONDirectory(dir)
+	for path, v := range map[string]any{directory.PeoplePath: p, directory.DepartmentsPath: d} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+		}
+	}
+	return directory, nil
+}
+
+// id returns a UUID shaped like the ids in the sample data, e.g.
+// 660e8400-e29b-41d4-a716-446655440001 for group 6 and n 1
+func id(group, n int) string {
+	return fmt.Sprintf("%d%d0e8400-e29b-41d4-a716-%012d", group, group, n)
+}
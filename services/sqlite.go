@@ -0,0 +1,497 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	// Pure-Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order; PRAGMA user_version records how many have run
+var migrations = []string{
+	`CREATE TABLE departments (
+		id                   TEXT PRIMARY KEY,
+		name                 TEXT NOT NULL,
+		phone                TEXT NOT NULL DEFAULT '',
+		manager_id           TEXT NOT NULL DEFAULT '',
+		parent_department_id TEXT
+	);
+	CREATE INDEX departments_parent_idx ON departments (parent_department_id);
+
+	CREATE TABLE people (
+		id            TEXT PRIMARY KEY,
+		first_name    TEXT NOT NULL,
+		last_name     TEXT NOT NULL,
+		prefix        TEXT,
+		room          TEXT NOT NULL DEFAULT '',
+		phone         TEXT NOT NULL DEFAULT '',
+		floor         INTEGER NOT NULL DEFAULT 0,
+		department_id TEXT NOT NULL DEFAULT '',
+		title         TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX people_department_idx ON people (department_id);`,
+
+	// Contact points are kept as a JSON list; they are only read with their person
+	`ALTER TABLE people ADD COLUMN contacts TEXT NOT NULL DEFAULT '';`,
+}
+
+const (
+	personColumns     = "id, first_name, last_name, prefix, room, phone, floor, department_id, title, contacts"
+	departmentColumns = "id, name, phone, manager_id, parent_department_id"
+)
+
+// SQLiteDirectory is a Directory backed by a SQLite database
+type SQLiteDirectory struct {
+	db   *sql.DB
+	path string
+}
+
+// OpenSQLite opens the database at path and applies any pending migrations
+func OpenSQLite(path string) (*SQLiteDirectory, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	d := &SQLiteDirectory{db: db, path: path}
+	if err := d.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// sqliteHeader starts every SQLite database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// OpenSQLiteReadOnly opens an existing database at path without writing to
+// it. No migrations are applied, so the schema must be the current one.
+func OpenSQLiteReadOnly(path string) (*SQLiteDirectory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	f.Close()
+	if err != nil || !bytes.Equal(header, sqliteHeader) {
+		return nil, fmt.Errorf("%s is not a SQLite database", path)
+	}
+
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read schema version of %s: %w", path, err)
+	}
+	switch {
+	case version == 0:
+		err = fmt.Errorf("%s is not a rehber database", path)
+	case version > len(migrations):
+		err = fmt.Errorf("%s has schema version %d, newer than supported version %d", path, version, len(migrations))
+	case version < len(migrations):
+		err = fmt.Errorf("%s has schema version %d, older than version %d; run db init on it to migrate", path, version, len(migrations))
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteDirectory{db: db, path: path}, nil
+}
+
+// Close closes the underlying database
+func (d *SQLiteDirectory) Close() error {
+	return d.db.Close()
+}
+
+// Migrate brings the schema up to date
+func (d *SQLiteDirectory) Migrate() error {
+	var version int
+	if err := d.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := d.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import replaces the contents of the database with the given people and departments
+func (d *SQLiteDirectory) Import(people []Person, departments []Department) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM people"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM departments"); err != nil {
+		return err
+	}
+
+	for _, dept := range departments {
+		if _, err := tx.Exec("INSERT INTO departments ("+departmentColumns+") VALUES (?, ?, ?, ?, ?)",
+			dept.Id, dept.Name, dept.Phone, dept.ManagerId, dept.ParentDepartmentId); err != nil {
+			return fmt.Errorf("failed to import department %s: %w", dept.Id, err)
+		}
+	}
+
+	for _, person := range people {
+		if _, err := tx.Exec("INSERT INTO people ("+personColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			person.Id, person.FirstName, person.LastName, person.Prefix, person.Room,
+			person.Phone, person.Floor, person.DepartmentId, person.Title, encodeContacts(person.Contacts)); err != nil {
+			return fmt.Errorf("failed to import person %s: %w", person.Id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPeople returns all people
+func (d *SQLiteDirectory) GetPeople() ([]Person, error) {
+	return d.queryPeople("SELECT " + personColumns + " FROM people ORDER BY rowid")
+}
+
+// GetPersonById finds a person by their Id
+func (d *SQLiteDirectory) GetPersonById(id string) (*Person, error) {
+	var person Person
+	err := scanPerson(d.db.QueryRow("SELECT "+personColumns+" FROM people WHERE id = ?", id), &person)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("person with Id %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// GetPeopleByDepartmentId returns all people in a specific department
+func (d *SQLiteDirectory) GetPeopleByDepartmentId(departmentId string) ([]Person, error) {
+	return d.queryPeople("SELECT "+personColumns+" FROM people WHERE department_id = ? ORDER BY rowid", departmentId)
+}
+
+// GetDepartments returns all departments
+func (d *SQLiteDirectory) GetDepartments() ([]Department, error) {
+	return d.queryDepartments("SELECT " + departmentColumns + " FROM departments ORDER BY rowid")
+}
+
+// GetDepartmentById finds a department by its Id
+func (d *SQLiteDirectory) GetDepartmentById(id string) (*Department, error) {
+	var dept Department
+	err := scanDepartment(d.db.QueryRow("SELECT "+departmentColumns+" FROM departments WHERE id = ?", id), &dept)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("department with Id %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dept, nil
+}
+
+// GetDepartmentsByParentId returns all departments with a specific parent department Id
+func (d *SQLiteDirectory) GetDepartmentsByParentId(parentId string) ([]Department, error) {
+	return d.queryDepartments("SELECT "+departmentColumns+" FROM departments WHERE parent_department_id = ? AND parent_department_id != '' ORDER BY rowid", parentId)
+}
+
+// GetTopLevelDepartments returns all departments without a parent
+func (d *SQLiteDirectory) GetTopLevelDepartments() ([]Department, error) {
+	return d.queryDepartments("SELECT " + departmentColumns + " FROM departments WHERE parent_department_id IS NULL OR parent_department_id = '' ORDER BY rowid")
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPerson(row scanner, person *Person) error {
+	var contacts string
+	if err := row.Scan(&person.Id, &person.FirstName, &person.LastName, &person.Prefix, &person.Room,
+		&person.Phone, &person.Floor, &person.DepartmentId, &person.Title, &contacts); err != nil {
+		return err
+	}
+	if contacts != "" {
+		if err := json.Unmarshal([]byte(contacts), &person.Contacts); err != nil {
+			return fmt.Errorf("person %s has invalid contacts: %w", person.Id, err)
+		}
+	}
+	person.syncPhone()
+	return nil
+}
+
+// encodeContacts stores contact points as JSON, or "" when there are none
+func encodeContacts(contacts []ContactPoint) string {
+	if len(contacts) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(contacts)
+	return string(data)
+}
+
+func scanDepartment(row scanner, dept *Department) error {
+	return row.Scan(&dept.Id, &dept.Name, &dept.Phone, &dept.ManagerId, &dept.ParentDepartmentId)
+}
+
+func (d *SQLiteDirectory) queryPeople(query string, args ...any) ([]Person, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query people: %w", err)
+	}
+	defer rows.Close()
+
+	var people []Person
+	for rows.Next() {
+		var person Person
+		if err := scanPerson(rows, &person); err != nil {
+			return nil, fmt.Errorf("failed to read person: %w", err)
+		}
+		people = append(people, person)
+	}
+
+	return people, rows.Err()
+}
+
+func (d *SQLiteDirectory) queryDepartments(query string, args ...any) ([]Department, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query departments: %w", err)
+	}
+	defer rows.Close()
+
+	var departments []Department
+	for rows.Next() {
+		var dept Department
+		if err := scanDepartment(rows, &dept); err != nil {
+			return nil, fmt.Errorf("failed to read department: %w", err)
+		}
+		departments = append(departments, dept)
+	}
+
+	return departments, rows.Err()
+}
+
+// CreatePerson validates a new person and inserts it
+func (d *SQLiteDirectory) CreatePerson(person Person) (*Person, error) {
+	departments, err := d.GetDepartments()
+	if err != nil {
+		return nil, err
+	}
+
+	if person.Id == "" {
+		person.Id = newId()
+	} else if _, err := d.GetPersonById(person.Id); err == nil {
+		return nil, fmt.Errorf("person with Id %s already exists", person.Id)
+	}
+	if err := validatePerson(person, departments); err != nil {
+		return nil, err
+	}
+
+	if _, err := d.db.Exec("INSERT INTO people ("+personColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		person.Id, person.FirstName, person.LastName, person.Prefix, person.Room,
+		person.Phone, person.Floor, person.DepartmentId, person.Title, encodeContacts(person.Contacts)); err != nil {
+		return nil, fmt.Errorf("failed to create person %s: %w", person.Id, err)
+	}
+
+	return &person, nil
+}
+
+// UpdatePerson validates and replaces the person with the same Id
+func (d *SQLiteDirectory) UpdatePerson(person Person) error {
+	if _, err := d.GetPersonById(person.Id); err != nil {
+		return err
+	}
+	departments, err := d.GetDepartments()
+	if err != nil {
+		return err
+	}
+	if err := validatePerson(person, departments); err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec(`UPDATE people SET first_name = ?, last_name = ?, prefix = ?, room = ?,
+		phone = ?, floor = ?, department_id = ?, title = ?, contacts = ? WHERE id = ?`,
+		person.FirstName, person.LastName, person.Prefix, person.Room,
+		person.Phone, person.Floor, person.DepartmentId, person.Title, encodeContacts(person.Contacts), person.Id); err != nil {
+		return fmt.Errorf("failed to update person %s: %w", person.Id, err)
+	}
+
+	return nil
+}
+
+// DeletePerson removes a person
+func (d *SQLiteDirectory) DeletePerson(id string) error {
+	if _, err := d.GetPersonById(id); err != nil {
+		return err
+	}
+	departments, err := d.GetDepartments()
+	if err != nil {
+		return err
+	}
+	if err := checkPersonDeletable(id, departments); err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec("DELETE FROM people WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete person %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// CreateDepartment validates a new department and inserts it
+func (d *SQLiteDirectory) CreateDepartment(dept Department) (*Department, error) {
+	people, departments, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if dept.Id == "" {
+		dept.Id = newId()
+	} else if findDepartment(departments, dept.Id) >= 0 {
+		return nil, fmt.Errorf("department with Id %s already exists", dept.Id)
+	}
+	if err := validateDepartment(dept, people, departments); err != nil {
+		return nil, err
+	}
+
+	if _, err := d.db.Exec("INSERT INTO departments ("+departmentColumns+") VALUES (?, ?, ?, ?, ?)",
+		dept.Id, dept.Name, dept.Phone, dept.ManagerId, dept.ParentDepartmentId); err != nil {
+		return nil, fmt.Errorf("failed to create department %s: %w", dept.Id, err)
+	}
+
+	return &dept, nil
+}
+
+// UpdateDepartment validates and replaces the department with the same Id
+func (d *SQLiteDirectory) UpdateDepartment(dept Department) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	if findDepartment(departments, dept.Id) < 0 {
+		return fmt.Errorf("department with Id %s not found", dept.Id)
+	}
+	if err := validateDepartment(dept, people, departments); err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec("UPDATE departments SET name = ?, phone = ?, manager_id = ?, parent_department_id = ? WHERE id = ?",
+		dept.Name, dept.Phone, dept.ManagerId, dept.ParentDepartmentId, dept.Id); err != nil {
+		return fmt.Errorf("failed to update department %s: %w", dept.Id, err)
+	}
+
+	return nil
+}
+
+// DeleteDepartment removes a department
+func (d *SQLiteDirectory) DeleteDepartment(id string) error {
+	people, departments, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	if findDepartment(departments, id) < 0 {
+		return fmt.Errorf("department with Id %s not found", id)
+	}
+	if err := checkDepartmentDeletable(id, people, departments); err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec("DELETE FROM departments WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete department %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// ApplyChanges validates departments and people against the merged data and
+// stores them in one transaction
+func (d *SQLiteDirectory) ApplyChanges(departments []Department, people []Person) error {
+	departments, people = assignIds(departments, people)
+	current, currentDepartments, err := d.load()
+	if err != nil {
+		return err
+	}
+	if _, _, err := mergeChanges(current, currentDepartments, departments, people); err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Upserts keep the rowid, and so the order, of replaced records
+	for _, dept := range departments {
+		if _, err := tx.Exec("INSERT INTO departments ("+departmentColumns+") VALUES (?, ?, ?, ?, ?)"+
+			` ON CONFLICT (id) DO UPDATE SET name = excluded.name, phone = excluded.phone,
+			manager_id = excluded.manager_id, parent_department_id = excluded.parent_department_id`,
+			dept.Id, dept.Name, dept.Phone, dept.ManagerId, dept.ParentDepartmentId); err != nil {
+			return fmt.Errorf("failed to store department %s: %w", dept.Id, err)
+		}
+	}
+	for _, person := range people {
+		if _, err := tx.Exec("INSERT INTO people ("+personColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"+
+			` ON CONFLICT (id) DO UPDATE SET first_name = excluded.first_name, last_name = excluded.last_name,
+			prefix = excluded.prefix, room = excluded.room, phone = excluded.phone, floor = excluded.floor,
+			department_id = excluded.department_id, title = excluded.title, contacts = excluded.contacts`,
+			person.Id, person.FirstName, person.LastName, person.Prefix, person.Room,
+			person.Phone, person.Floor, person.DepartmentId, person.Title, encodeContacts(person.Contacts)); err != nil {
+			return fmt.Errorf("failed to store person %s: %w", person.Id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// load reads both tables for validating a change
+func (d *SQLiteDirectory) load() ([]Person, []Department, error) {
+	people, err := d.GetPeople()
+	if err != nil {
+		return nil, nil, err
+	}
+	departments, err := d.GetDepartments()
+	if err != nil {
+		return nil, nil, err
+	}
+	return people, departments, nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// createDatabase writes a database at the given schema version, running the
+// migrations up to it and then the given statements
+func createDatabase(t *testing.T, version int, statements ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rehber.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	schema := slices.Clone(migrations[:min(version, len(migrations))])
+	for _, statement := range append(schema, statements...) {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func schemaVersion(t *testing.T, d *SQLiteDirectory) int {
+	t.Helper()
+	var version int
+	if err := d.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestOpenSQLiteCreatesSchema(t *testing.T) {
+	d, err := OpenSQLite(filepath.Join(t.TempDir(), "rehber.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if got := schemaVersion(t, d); got != len(migrations) {
+		t.Errorf("schema version is %d, want %d", got, len(migrations))
+	}
+	if people, err := d.GetPeople(); err != nil || len(people) != 0 {
+		t.Errorf("GetPeople on a new database = %v, %v; want no people", people, err)
+	}
+
+	// Opening again applies nothing
+	d.Close()
+	d, err = OpenSQLite(d.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if got := schemaVersion(t, d); got != len(migrations) {
+		t.Errorf("schema version after reopening is %d, want %d", got, len(migrations))
+	}
+}
+
+func TestOpenSQLiteMigratesFromVersion1(t *testing.T) {
+	path := createDatabase(t, 1,
+		`INSERT INTO departments (id, name, manager_id, parent_department_id) VALUES ('eng', 'Engineering', 'ali', NULL)`,
+		`INSERT INTO people (id, first_name, last_name, phone, department_id) VALUES ('ali', 'Ali', 'Yılmaz', '+90-212-555-1001', 'eng')`,
+	)
+
+	d, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if got := schemaVersion(t, d); got != len(migrations) {
+		t.Fatalf("schema version is %d, want %d", got, len(migrations))
+	}
+	person, err := d.GetPersonById("ali")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.FullName() != "Ali Yılmaz" || person.Phone != "+90-212-555-1001" || person.Contacts != nil {
+		t.Errorf("migrated person is %+v", person)
+	}
+
+	// The column added by the second migration stores contact points
+	person.Contacts = []ContactPoint{{Kind: ContactEmail, Value: "ali@example.com"}}
+	if err := d.UpdatePerson(*person); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := d.GetPersonById("ali")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.Contacts, person.Contacts) {
+		t.Errorf("contacts are %v, want %v", updated.Contacts, person.Contacts)
+	}
+}
+
+func TestOpenSQLiteRejectsNewerSchema(t *testing.T) {
+	path := createDatabase(t, len(migrations)+1)
+	if d, err := OpenSQLite(path); err == nil {
+		d.Close()
+		t.Fatal("expected an error for a schema newer than the supported version")
+	}
+}
+
+func TestImportReplacesContents(t *testing.T) {
+	d, err := OpenSQLite(filepath.Join(t.TempDir(), "rehber.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	parent := func(id string) *string { return &id }
+	prefix := "Dr."
+	departments := []Department{
+		{Id: "root", Name: "Root", Phone: "+90-312-555-0000", ManagerId: "ali"},
+		{Id: "eng", Name: "Engineering", ParentDepartmentId: parent("root")},
+	}
+	people := []Person{
+		{Id: "ali", FirstName: "Ali", LastName: "Yılmaz", Prefix: &prefix, Room: "A-101", Phone: "+90-212-555-1001", Floor: 1, DepartmentId: "root", Title: "Director"},
+		{Id: "ayse", FirstName: "Ayşe", LastName: "Demir", Phone: "+90-212-555-1002", DepartmentId: "eng",
+			Contacts: []ContactPoint{{Kind: ContactMobile, Value: "+90-532-555-1002", Primary: true}}},
+	}
+
+	if err := d.Import([]Person{{Id: "old", FirstName: "Eski", LastName: "Kayıt"}}, []Department{{Id: "old", Name: "Old"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Import(people, departments); err != nil {
+		t.Fatal(err)
+	}
+
+	gotPeople, err := d.GetPeople()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotPeople, people) {
+		t.Errorf("people are %+v, want %+v", gotPeople, people)
+	}
+	gotDepartments, err := d.GetDepartments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotDepartments, departments) {
+		t.Errorf("departments are %+v, want %+v", gotDepartments, departments)
+	}
+}
+
+func TestImportRollsBackOnError(t *testing.T) {
+	d, err := OpenSQLite(filepath.Join(t.TempDir(), "rehber.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	before := []Department{{Id: "eng", Name: "Engineering"}}
+	if err := d.Import(nil, before); err != nil {
+		t.Fatal(err)
+	}
+	// The duplicate Id fails on insert, after the tables were emptied
+	if err := d.Import(nil, []Department{{Id: "dup", Name: "A"}, {Id: "dup", Name: "B"}}); err == nil {
+		t.Fatal("expected an error for a duplicate department Id")
+	}
+
+	got, err := d.GetDepartments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, before) {
+		t.Errorf("departments are %+v after a failed import, want %+v", got, before)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import "fmt"
+
+// Store is an in-memory Directory that is loaded once and indexed for lookups
+type Store struct {
+	people      []Person
+	departments []Department
+
+	personIndex     map[string]int
+	departmentIndex map[string]int
+	peopleByDept    map[string][]int
+	deptsByParent   map[string][]int
+	topLevel        []int
+}
+
+// NewStore builds an indexed store from the given people and departments
+func NewStore(people []Person, departments []Department) *Store {
+	s := &Store{
+		people:          people,
+		departments:     departments,
+		personIndex:     make(map[string]int, len(people)),
+		departmentIndex: make(map[string]int, len(departments)),
+		peopleByDept:    make(map[string][]int),
+		deptsByParent:   make(map[string][]int),
+	}
+
+	for i := range people {
+		// Keep the first record when ids are duplicated, like a linear scan would
+		if _, ok := s.personIndex[people[i].Id]; !ok {
+			s.personIndex[people[i].Id] = i
+		}
+		s.peopleByDept[people[i].DepartmentId] = append(s.peopleByDept[people[i].DepartmentId], i)
+	}
+
+	for i := range departments {
+		if _, ok := s.departmentIndex[departments[i].Id]; !ok {
+			s.departmentIndex[departments[i].Id] = i
+		}
+		if !departments[i].HasParent() {
+			s.topLevel = append(s.topLevel, i)
+		} else {
+			parentId := *departments[i].ParentDepartmentId
+			s.deptsByParent[parentId] = append(s.deptsByParent[parentId], i)
+		}
+	}
+
+	return s
+}
+
+// Load reads all people and departments from src once and returns them as an indexed store
+func Load(src Directory) (*Store, error) {
+	people, err := src.GetPeople()
+	if err != nil {
+		return nil, err
+	}
+
+	departments, err := src.GetDepartments()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(people, departments), nil
+}
+
+// LoadAvailable is like Load but keeps going when one of the sources fails,
+// returning a store with whatever could be read and the errors encountered
+func LoadAvailable(src Directory) (*Store, []error) {
+	var errs []error
+
+	people, err := src.GetPeople()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	departments, err := src.GetDepartments()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return NewStore(people, departments), errs
+}
+
+// GetPeople returns all people
+func (s *Store) GetPeople() ([]Person, error) {
+	result := make([]Person, len(s.people))
+	copy(result, s.people)
+	return result, nil
+}
+
+// GetPersonById finds a person by their Id
+func (s *Store) GetPersonById(id string) (*Person, error) {
+	i, ok := s.personIndex[id]
+	if !ok {
+		return nil, fmt.Errorf("person with Id %s not found", id)
+	}
+
+	person := s.people[i]
+	return &person, nil
+}
+
+// GetPeopleByDepartmentId returns all people in a specific department
+func (s *Store) GetPeopleByDepartmentId(departmentId string) ([]Person, error) {
+	return s.collectPeople(s.peopleByDept[departmentId]), nil
+}
+
+// GetDepartments returns all departments
+func (s *Store) GetDepartments() ([]Department, error) {
+	result := make([]Department, len(s.departments))
+	copy(result, s.departments)
+	return result, nil
+}
+
+// GetDepartmentById finds a department by its Id
+func (s *Store) GetDepartmentById(id string) (*Department, error) {
+	i, ok := s.departmentIndex[id]
+	if !ok {
+		return nil, fmt.Errorf("department with Id %s not found", id)
+	}
+
+	department := s.departments[i]
+	return &department, nil
+}
+
+// GetDepartmentsByParentId returns all departments with a specific parent department Id
+func (s *Store) GetDepartmentsByParentId(parentId string) ([]Department, error) {
+	return s.collectDepartments(s.deptsByParent[parentId]), nil
+}
+
+// GetTopLevelDepartments returns all departments without a parent
+func (s *Store) GetTopLevelDepartments() ([]Department, error) {
+	return s.collectDepartments(s.topLevel), nil
+}
+
+func (s *Store) collectPeople(indexes []int) []Person {
+	var result []Person
+	for _, i := range indexes {
+		result = append(result, s.people[i])
+	}
+	return result
+}
+
+func (s *Store) collectDepartments(indexes []int) []Department {
+	var result []Department
+	for _, i := range indexes {
+		result = append(result, s.departments[i])
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/services/servicestest"
+)
+
+// benchmarkSize is the number of people in the generated benchmark directory
+const benchmarkSize = 100_000
+
+func BenchmarkLoadJSON(b *testing.B) {
+	dir, err := servicestest.WriteJSON(b.TempDir(), benchmarkSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		if _, err := services.Load(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewStore(b *testing.B) {
+	people, departments := servicestest.Generate(benchmarkSize)
+
+	for b.Loop() {
+		services.NewStore(people, departments)
+	}
+}
+
+func BenchmarkStoreLookups(b *testing.B) {
+	people, departments := servicestest.Generate(benchmarkSize)
+	store := services.NewStore(people, departments)
+
+	for i := 0; b.Loop(); i++ {
+		person, err := store.GetPersonById(people[i%len(people)].Id)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := store.GetDepartmentById(person.DepartmentId); err != nil {
+			b.Fatal(err)
+		}
+		store.GetPeopleByDepartmentId(person.DepartmentId)
+	}
+}
+
+func TestGeneratedDirectoryIsValid(t *testing.T) {
+	people, departments := servicestest.Generate(1_050)
+	if len(departments) != 11 {
+		t.Fatalf("got %d departments, want 11", len(departments))
+	}
+	if problems := services.Validate(people, departments); len(problems) > 0 {
+		t.Fatalf("generated directory has problems: %v", problems)
+	}
+}
+
+func TestEmptyParentIsTopLevel(t *testing.T) {
+	empty, root := "", "root"
+	departments := []services.Department{
+		{Id: "root", Name: "Root"},
+		{Id: "blank", Name: "Blank parent", ParentDepartmentId: &empty},
+		{Id: "child", Name: "Child", ParentDepartmentId: &root},
+	}
+
+	dir := services.NewJSONDirectory(t.TempDir())
+	for _, path := range []string{dir.PeoplePath, dir.DepartmentsPath} {
+		if err := os.WriteFile(path, []byte("[]"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dir.ApplyChanges(departments, nil); err != nil {
+		t.Fatal(err)
+	}
+	db, err := services.OpenSQLite(filepath.Join(t.TempDir(), "rehber.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Import(nil, departments); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, dir := range map[string]services.Directory{"store": services.NewStore(nil, departments), "json": dir, "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			topLevel, err := dir.GetTopLevelDepartments()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, dept := range topLevel {
+				ids = append(ids, dept.Id)
+			}
+			if !slices.Equal(ids, []string{"root", "blank"}) {
+				t.Errorf("top-level departments are %v, want [root blank]", ids)
+			}
+			if children, _ := dir.GetDepartmentsByParentId(""); len(children) > 0 {
+				t.Errorf("departments with an empty parent are listed as children of %q", "")
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProblemKind classifies a data integrity problem
+type ProblemKind string
+
+const (
+	ProblemDanglingReference ProblemKind = "dangling-reference"
+	ProblemCycle             ProblemKind = "cycle"
+	ProblemDuplicateId       ProblemKind = "duplicate-id"
+	ProblemDuplicatePhone    ProblemKind = "duplicate-phone"
+	ProblemManagerNotMember  ProblemKind = "manager-not-member"
+	ProblemEmptyName         ProblemKind = "empty-name"
+	ProblemInvalidPhone      ProblemKind = "invalid-phone"
+	ProblemInvalidContact    ProblemKind = "invalid-contact"
+)
+
+// Entity types a Problem can refer to
+const (
+	EntityPerson     = "person"
+	EntityDepartment = "department"
+)
+
+// Problem describes a single data integrity issue
+type Problem struct {
+	Kind    ProblemKind `json:"kind" yaml:"kind"`
+	Entity  string      `json:"entity" yaml:"entity"`
+	Id      string      `json:"id" yaml:"id"`
+	Field   string      `json:"field,omitempty" yaml:"field,omitempty"`
+	Value   string      `json:"value,omitempty" yaml:"value,omitempty"`
+	Message string      `json:"message" yaml:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s: %s", p.Entity, p.Id, p.Message)
+}
+
+// Validate checks people and departments for integrity problems and reports
+// all of them. Empty references mean unset, as in the write API, and are not
+// reported.
+func Validate(people []Person, departments []Department) []Problem {
+	var problems []Problem
+	add := func(kind ProblemKind, entity, id, field, value, format string, args ...any) {
+		problems = append(problems, Problem{
+			Kind:    kind,
+			Entity:  entity,
+			Id:      id,
+			Field:   field,
+			Value:   value,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	personById := make(map[string]*Person, len(people))
+	for i := range people {
+		person := &people[i]
+		if _, ok := personById[person.Id]; ok {
+			add(ProblemDuplicateId, EntityPerson, person.Id, "id", person.Id, "duplicate person Id")
+			continue
+		}
+		personById[person.Id] = person
+	}
+
+	deptById := make(map[string]*Department, len(departments))
+	for i := range departments {
+		dept := &departments[i]
+		if _, ok := deptById[dept.Id]; ok {
+			add(ProblemDuplicateId, EntityDepartment, dept.Id, "id", dept.Id, "duplicate department Id")
+			continue
+		}
+		deptById[dept.Id] = dept
+	}
+
+	for _, person := range people {
+		if strings.TrimSpace(person.FirstName) == "" {
+			add(ProblemEmptyName, EntityPerson, person.Id, "firstName", "", "first name is empty")
+		}
+		if strings.TrimSpace(person.LastName) == "" {
+			add(ProblemEmptyName, EntityPerson, person.Id, "lastName", "", "last name is empty")
+		}
+		if person.Phone != "" {
+			if _, err := ParsePhone(person.Phone); err != nil {
+				add(ProblemInvalidPhone, EntityPerson, person.Id, "phone", person.Phone, "%v", err)
+			}
+		}
+		primaries := 0
+		for i, contact := range person.Contacts {
+			field := fmt.Sprintf("contacts[%d]", i)
+			if err := validateContact(contact); err != nil {
+				kind := ProblemInvalidContact
+				if contact.Kind.IsPhone() {
+					kind = ProblemInvalidPhone
+				}
+				add(kind, EntityPerson, person.Id, field, contact.Value, "%v", err)
+			}
+			if contact.Primary {
+				primaries++
+			}
+		}
+		if primaries > 1 {
+			add(ProblemInvalidContact, EntityPerson, person.Id, "contacts", "", "%d contacts are marked primary", primaries)
+		}
+		if _, ok := deptById[person.DepartmentId]; !ok && person.DepartmentId != "" {
+			add(ProblemDanglingReference, EntityPerson, person.Id, "departmentId", person.DepartmentId,
+				"department %q not found", person.DepartmentId)
+		}
+	}
+
+	for _, dept := range departments {
+		if strings.TrimSpace(dept.Name) == "" {
+			add(ProblemEmptyName, EntityDepartment, dept.Id, "name", "", "name is empty")
+		}
+		if dept.Phone != "" {
+			if _, err := ParsePhone(dept.Phone); err != nil {
+				add(ProblemInvalidPhone, EntityDepartment, dept.Id, "phone", dept.Phone, "%v", err)
+			}
+		}
+
+		switch manager, ok := personById[dept.ManagerId]; {
+		case dept.ManagerId == "":
+			// A department without a manager is allowed
+		case !ok:
+			add(ProblemDanglingReference, EntityDepartment, dept.Id, "managerId", dept.ManagerId,
+				"manager %q not found", dept.ManagerId)
+		case manager.DepartmentId != dept.Id:
+			add(ProblemManagerNotMember, EntityDepartment, dept.Id, "managerId", dept.ManagerId,
+				"manager %s %s is not a member of %s", manager.FirstName, manager.LastName, dept.Name)
+		}
+
+		if dept.ParentDepartmentId != nil && *dept.ParentDepartmentId != "" {
+			if _, ok := deptById[*dept.ParentDepartmentId]; !ok {
+				add(ProblemDanglingReference, EntityDepartment, dept.Id, "parentDepartmentId", *dept.ParentDepartmentId,
+					"parent department %q not found", *dept.ParentDepartmentId)
+			}
+		}
+	}
+
+	for _, cycle := range findCycles(departments, deptById) {
+		add(ProblemCycle, EntityDepartment, cycle[0], "parentDepartmentId", "",
+			"parent departments form a cycle: %s", strings.Join(cycle, " → "))
+	}
+
+	problems = append(problems, duplicatePhones(people, departments)...)
+
+	return problems
+}
+
+// findCycles returns every cycle in the parent department graph, each starting at its smallest Id
+func findCycles(departments []Department, deptById map[string]*Department) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(deptById))
+
+	var cycles [][]string
+	for _, dept := range departments {
+		if state[dept.Id] != unvisited {
+			continue
+		}
+
+		// Follow parent links until reaching a finished node, a missing parent or the current path
+		var path []string
+		id := dept.Id
+		for {
+			if _, ok := deptById[id]; !ok || state[id] == done {
+				break
+			}
+			if state[id] == visiting {
+				start := 0
+				for path[start] != id {
+					start++
+				}
+				cycles = append(cycles, rotateToSmallest(path[start:]))
+				break
+			}
+
+			state[id] = visiting
+			path = append(path, id)
+
+			parent := deptById[id].ParentDepartmentId
+			if parent == nil {
+				break
+			}
+			id = *parent
+		}
+
+		for _, visited := range path {
+			state[visited] = done
+		}
+	}
+
+	return cycles
+}
+
+// rotateToSmallest rotates a cycle so it starts at its smallest Id and repeats it at the end
+func rotateToSmallest(cycle []string) []string {
+	smallest := 0
+	for i := range cycle {
+		if cycle[i] < cycle[smallest] {
+			smallest = i
+		}
+	}
+
+	result := make([]string, 0, len(cycle)+1)
+	result = append(result, cycle[smallest:]...)
+	result = append(result, cycle[:smallest]...)
+	return append(result, cycle[smallest])
+}
+
+// duplicatePhones reports every record sharing a phone number with an earlier record
+func duplicatePhones(people []Person, departments []Department) []Problem {
+	type owner struct {
+		entity, id, name string
+	}
+	owners := make(map[string][]owner)
+	var order []string
+
+	record := func(phone string, o owner) {
+		key := phoneKey(phone)
+		if key == "" {
+			return
+		}
+		if _, ok := owners[key]; !ok {
+			order = append(order, key)
+		}
+		// Duplicated records are already reported as duplicate ids
+		for _, existing := range owners[key] {
+			if existing.entity == o.entity && existing.id == o.id {
+				return
+			}
+		}
+		owners[key] = append(owners[key], o)
+	}
+	for _, person := range people {
+		record(person.Phone, owner{EntityPerson, person.Id, person.FirstName + " " + person.LastName})
+	}
+	for _, dept := range departments {
+		record(dept.Phone, owner{EntityDepartment, dept.Id, dept.Name})
+	}
+
+	var problems []Problem
+	for _, key := range order {
+		list := owners[key]
+		if len(list) < 2 {
+			continue
+		}
+		for _, dup := range list[1:] {
+			problems = append(problems, Problem{
+				Kind:    ProblemDuplicatePhone,
+				Entity:  dup.entity,
+				Id:      dup.id,
+				Field:   "phone",
+				Value:   key,
+				Message: fmt.Sprintf("phone %s is also used by %s %s (%s)", key, list[0].entity, list[0].id, list[0].name),
+			})
+		}
+	}
+
+	return problems
+}
+
+// phoneKey returns the E.164 form of a phone number with its extension, or
+// its digits when it cannot be parsed, so formatting differences do not hide duplicates
+func phoneKey(phone string) string {
+	p, err := ParsePhone(phone)
+	if err != nil || p.E164() == "" {
+		return phoneDigits(phone)
+	}
+	if p.Extension != "" {
+		return p.E164() + " x" + p.Extension
+	}
+	return p.E164()
+}
+
+// phoneDigits strips everything but digits so formatting differences do not hide duplicates
+func phoneDigits(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the burst of events a single save produces into one reload
+const watchDebounce = 200 * time.Millisecond
+
+// Watchable is implemented by directories whose data lives in files that can be watched
+type Watchable interface {
+	// SourcePaths returns the files the directory reads from
+	SourcePaths() []string
+}
+
+// SourcePaths returns the people and departments files
+func (d *JSONDirectory) SourcePaths() []string {
+	return []string{d.PeoplePath, d.DepartmentsPath}
+}
+
+// SourcePaths returns the database file
+func (d *SQLiteDirectory) SourcePaths() []string {
+	return []string{d.path}
+}
+
+// Watcher calls a function whenever one of a set of files changes
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Watch starts watching paths and calls onChange after they are modified, replaced or removed.
+// The parent directories are watched so editors that save by renaming a new file into place
+// are still noticed.
+func Watch(paths []string, onChange func()) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	files := make(map[string]bool, len(paths))
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			fw.Close()
+			return nil, err
+		}
+		files[abs] = true
+
+		dir := filepath.Dir(abs)
+		if dirs[dir] {
+			continue
+		}
+		if err := fw.Add(dir); err != nil {
+			fw.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	w := &Watcher{watcher: fw, done: make(chan struct{})}
+	w.wg.Add(1)
+	go w.run(files, onChange)
+
+	return w, nil
+}
+
+func (w *Watcher) run(files map[string]bool, onChange func()) {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !files[event.Name] || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if timer == nil {
+				timer = time.AfterFunc(watchDebounce, onChange)
+			} else {
+				timer.Reset(watchDebounce)
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	close(w.done)
+	err := w.watcher.Close()
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Editor is a Directory that can also create, update and delete records
+type Editor interface {
+	Directory
+
+	// CreatePerson validates and stores a new person, generating an Id when empty
+	CreatePerson(person Person) (*Person, error)
+	// UpdatePerson replaces the person with the same Id
+	UpdatePerson(person Person) error
+	// DeletePerson removes a person who does not manage any department
+	DeletePerson(id string) error
+
+	// CreateDepartment validates and stores a new department, generating an Id when empty
+	CreateDepartment(dept Department) (*Department, error)
+	// UpdateDepartment replaces the department with the same Id
+	UpdateDepartment(dept Department) error
+	// DeleteDepartment removes a department that has no members or sub-departments
+	DeleteDepartment(id string) error
+
+	// ApplyChanges stores departments and people in one write, replacing the
+	// records whose Id exists and creating the others, with Ids generated when
+	// empty. Nothing is written unless every record is valid.
+	ApplyChanges(departments []Department, people []Person) error
+}
+
+// newId generates a random UUID like the ids in the bundled data
+func newId() string {
+	return uuid.NewString()
+}
+
+// validatePerson checks a person's fields and references against the current data
+func validatePerson(person Person, departments []Department) error {
+	if strings.TrimSpace(person.Id) == "" {
+		return fmt.Errorf("person Id is required")
+	}
+	if strings.TrimSpace(person.FirstName) == "" {
+		return fmt.Errorf("person %s: first name is required", person.Id)
+	}
+	if strings.TrimSpace(person.LastName) == "" {
+		return fmt.Errorf("person %s: last name is required", person.Id)
+	}
+	if person.Floor < 0 {
+		return fmt.Errorf("person %s: floor cannot be negative", person.Id)
+	}
+	primaries := 0
+	for _, contact := range person.Contacts {
+		if err := validateContact(contact); err != nil {
+			return fmt.Errorf("person %s: %w", person.Id, err)
+		}
+		if contact.Primary {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return fmt.Errorf("person %s: only one contact can be primary", person.Id)
+	}
+	if person.DepartmentId != "" && findDepartment(departments, person.DepartmentId) < 0 {
+		return fmt.Errorf("person %s: department with Id %s not found", person.Id, person.DepartmentId)
+	}
+
+	return nil
+}
+
+// validateDepartment checks a department's fields and references against the current data
+func validateDepartment(dept Department, people []Person, departments []Department) error {
+	if strings.TrimSpace(dept.Id) == "" {
+		return fmt.Errorf("department Id is required")
+	}
+	if strings.TrimSpace(dept.Name) == "" {
+		return fmt.Errorf("department %s: name is required", dept.Id)
+	}
+	if dept.ManagerId != "" && findPerson(people, dept.ManagerId) < 0 {
+		return fmt.Errorf("department %s: manager with Id %s not found", dept.Id, dept.ManagerId)
+	}
+
+	if dept.ParentDepartmentId != nil {
+		// Walk up from the new parent; reaching dept itself means the change creates a cycle
+		parentId := *dept.ParentDepartmentId
+		seen := map[string]bool{}
+		for parentId != "" && !seen[parentId] {
+			if parentId == dept.Id {
+				return fmt.Errorf("department %s: parent department would create a cycle", dept.Id)
+			}
+			seen[parentId] = true
+
+			i := findDepartment(departments, parentId)
+			if i < 0 {
+				return fmt.Errorf("department %s: parent department with Id %s not found", dept.Id, parentId)
+			}
+			if departments[i].ParentDepartmentId == nil {
+				break
+			}
+			parentId = *departments[i].ParentDepartmentId
+		}
+	}
+
+	return nil
+}
+
+// assignIds returns copies of departments and people with Ids generated where empty
+func assignIds(departments []Department, people []Person) ([]Department, []Person) {
+	departments, people = slices.Clone(departments), slices.Clone(people)
+	for i := range departments {
+		if departments[i].Id == "" {
+			departments[i].Id = newId()
+		}
+	}
+	for i := range people {
+		if people[i].Id == "" {
+			people[i].Id = newId()
+		}
+	}
+	return departments, people
+}
+
+// mergeChanges returns the current data with the changed departments and
+// people applied, after validating every changed record against the result
+func mergeChanges(people []Person, departments []Department, changedDepartments []Department, changedPeople []Person) ([]Person, []Department, error) {
+	people, departments = slices.Clone(people), slices.Clone(departments)
+
+	departmentsById := departmentIndex(departments)
+	for _, dept := range changedDepartments {
+		if i, ok := departmentsById[dept.Id]; ok {
+			departments[i] = dept
+		} else {
+			departmentsById[dept.Id] = len(departments)
+			departments = append(departments, dept)
+		}
+	}
+
+	peopleById := personIndex(people)
+	for _, person := range changedPeople {
+		if i, ok := peopleById[person.Id]; ok {
+			people[i] = person
+		} else {
+			peopleById[person.Id] = len(people)
+			people = append(people, person)
+		}
+	}
+
+	for _, dept := range changedDepartments {
+		if err := validateDepartment(dept, people, departments); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, person := range changedPeople {
+		if err := validatePerson(person, departments); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return people, departments, nil
+}
+
+// checkPersonDeletable rejects deleting a person who still manages a department
+func checkPersonDeletable(id string, departments []Department) error {
+	for _, dept := range departments {
+		if dept.ManagerId == id {
+			return fmt.Errorf("person %s manages department %s", id, dept.Name)
+		}
+	}
+	return nil
+}
+
+// checkDepartmentDeletable rejects deleting a department that still has members or children
+func checkDepartmentDeletable(id string, people []Person, departments []Department) error {
+	for _, person := range people {
+		if person.DepartmentId == id {
+			return fmt.Errorf("department %s still has members", id)
+		}
+	}
+	for _, dept := range departments {
+		if dept.ParentDepartmentId != nil && *dept.ParentDepartmentId == id {
+			return fmt.Errorf("department %s still has sub-departments", id)
+		}
+	}
+	return nil
+}
+
+// personIndex maps each person Id to its first position in people
+func personIndex(people []Person) map[string]int {
+	index := make(map[string]int, len(people))
+	for i, person := range people {
+		if _, ok := index[person.Id]; !ok {
+			index[person.Id] = i
+		}
+	}
+	return index
+}
+
+// departmentIndex maps each department Id to its first position in departments
+func departmentIndex(departments []Department) map[string]int {
+	index := make(map[string]int, len(departments))
+	for i, dept := range departments {
+		if _, ok := index[dept.Id]; !ok {
+			index[dept.Id] = i
+		}
+	}
+	return index
+}
+
+func findPerson(people []Person, id string) int {
+	for i := range people {
+		if people[i].Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findDepartment(departments []Department, id string) int {
+	for i := range departments {
+		if departments[i].Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeJSONAtomic writes v to path through a temporary file and a rename,
+// so readers never observe a partially written file
+func writeJSONAtomic(path string, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	// Cleanup is a no-op once the rename has succeeded
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/htekgulds/terminal-rehber/services"
+	"github.com/htekgulds/terminal-rehber/services/servicestest"
+)
+
+// editors returns a JSON and a SQLite editor holding the same generated directory
+func editors(t *testing.T, size int) map[string]services.Editor {
+	t.Helper()
+	dir, err := servicestest.WriteJSON(t.TempDir(), size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := services.OpenSQLite(filepath.Join(t.TempDir(), "rehber.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Import(servicestest.Generate(size)); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]services.Editor{"json": dir, "sqlite": db}
+}
+
+func TestApplyChanges(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			before, _ := editor.GetPeople()
+			departments, _ := editor.GetDepartments()
+
+			updated := before[1]
+			updated.Title = "Updated"
+			err := editor.ApplyChanges(
+				[]services.Department{{Id: "new-dept", Name: "New Department"}},
+				[]services.Person{
+					updated,
+					{FirstName: "Yeni", LastName: "Kişi", DepartmentId: "new-dept"},
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			people, _ := editor.GetPeople()
+			if len(people) != len(before)+1 {
+				t.Fatalf("got %d people, want %d", len(people), len(before)+1)
+			}
+			if people[1].Id != updated.Id || people[1].Title != "Updated" {
+				t.Errorf("updated person is %+v, want %s in place with the new title", people[1], updated.Id)
+			}
+			created := people[len(people)-1]
+			if created.Id == "" || created.DepartmentId != "new-dept" {
+				t.Errorf("created person is %+v, want a generated Id in new-dept", created)
+			}
+			after, _ := editor.GetDepartments()
+			if len(after) != len(departments)+1 {
+				t.Errorf("got %d departments, want %d", len(after), len(departments)+1)
+			}
+			if problems := services.Validate(people, after); len(problems) > 0 {
+				t.Errorf("directory has problems after the changes: %v", problems)
+			}
+		})
+	}
+}
+
+func TestApplyChangesWritesNothingWhenInvalid(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			before, _ := editor.GetPeople()
+			departments, _ := editor.GetDepartments()
+
+			updated := before[0]
+			updated.Title = "Updated"
+			err := editor.ApplyChanges(
+				[]services.Department{{Id: "new-dept", Name: "New Department"}},
+				[]services.Person{
+					updated,
+					{FirstName: "Yeni", LastName: "Kişi", DepartmentId: "missing"},
+				},
+			)
+			if err == nil {
+				t.Fatal("expected an error for a person in a missing department")
+			}
+
+			people, _ := editor.GetPeople()
+			if len(people) != len(before) || people[0].Title != before[0].Title {
+				t.Errorf("people changed although the batch was rejected")
+			}
+			if got, _ := editor.GetDepartments(); len(got) != len(departments) {
+				t.Errorf("got %d departments, want %d", len(got), len(departments))
+			}
+		})
+	}
+}
+
+func TestPersonCRUD(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			departments, _ := editor.GetDepartments()
+
+			created, err := editor.CreatePerson(services.Person{FirstName: "Yeni", LastName: "Kişi", DepartmentId: departments[1].Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Id == "" {
+				t.Fatal("created person has no Id")
+			}
+			if got, err := editor.GetPersonById(created.Id); err != nil || got.FullName() != "Yeni Kişi" {
+				t.Fatalf("GetPersonById after create = %+v, %v", got, err)
+			}
+
+			created.Title = "Updated"
+			if err := editor.UpdatePerson(*created); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := editor.GetPersonById(created.Id); got == nil || got.Title != "Updated" {
+				t.Errorf("person after update is %+v, want the new title", got)
+			}
+
+			if err := editor.DeletePerson(created.Id); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := editor.GetPersonById(created.Id); err == nil {
+				t.Error("person is still found after delete")
+			}
+		})
+	}
+}
+
+func TestPersonWritesRejected(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			people, _ := editor.GetPeople()
+			departments, _ := editor.GetDepartments()
+			valid := func(edit func(p *services.Person)) services.Person {
+				p := services.Person{FirstName: "Yeni", LastName: "Kişi", DepartmentId: departments[0].Id}
+				edit(&p)
+				return p
+			}
+
+			for _, tt := range []struct {
+				name   string
+				person services.Person
+				want   string
+			}{
+				{"duplicate Id", valid(func(p *services.Person) { p.Id = people[1].Id }), "already exists"},
+				{"missing first name", valid(func(p *services.Person) { p.FirstName = " " }), "first name is required"},
+				{"missing last name", valid(func(p *services.Person) { p.LastName = "" }), "last name is required"},
+				{"negative floor", valid(func(p *services.Person) { p.Floor = -1 }), "floor cannot be negative"},
+				{"missing department", valid(func(p *services.Person) { p.DepartmentId = "missing" }), "department with Id missing not found"},
+				{"invalid contact", valid(func(p *services.Person) {
+					p.Contacts = []services.ContactPoint{{Kind: "pager", Value: "123"}}
+				}), "pager"},
+				{"two primaries", valid(func(p *services.Person) {
+					p.Contacts = []services.ContactPoint{
+						{Kind: services.ContactMobile, Value: "+90-532-555-0001", Primary: true},
+						{Kind: services.ContactEmail, Value: "yeni@example.com", Primary: true},
+					}
+				}), "only one contact can be primary"},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					_, err := editor.CreatePerson(tt.person)
+					if err == nil || !strings.Contains(err.Error(), tt.want) {
+						t.Errorf("CreatePerson error = %v, want one containing %q", err, tt.want)
+					}
+				})
+			}
+
+			invalid := people[1]
+			invalid.LastName = ""
+			if err := editor.UpdatePerson(invalid); err == nil {
+				t.Error("UpdatePerson accepted a person without a last name")
+			}
+			if err := editor.UpdatePerson(services.Person{Id: "missing", FirstName: "Yok", LastName: "Kişi"}); err == nil {
+				t.Error("UpdatePerson accepted an unknown Id")
+			}
+			if err := editor.DeletePerson("missing"); err == nil {
+				t.Error("DeletePerson accepted an unknown Id")
+			}
+			// The first person manages the first department
+			if err := editor.DeletePerson(people[0].Id); err == nil || !strings.Contains(err.Error(), "manages department") {
+				t.Errorf("DeletePerson of a manager error = %v, want the manager guard", err)
+			}
+
+			if after, _ := editor.GetPeople(); len(after) != len(people) || after[1].LastName != people[1].LastName {
+				t.Error("people changed although every write was rejected")
+			}
+		})
+	}
+}
+
+func TestDepartmentCRUD(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			departments, _ := editor.GetDepartments()
+			people, _ := editor.GetPeople()
+			parentId := departments[0].Id
+
+			created, err := editor.CreateDepartment(services.Department{Name: "New Department", ParentDepartmentId: &parentId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Id == "" {
+				t.Fatal("created department has no Id")
+			}
+			children, _ := editor.GetDepartmentsByParentId(parentId)
+			if !slices.ContainsFunc(children, func(d services.Department) bool { return d.Id == created.Id }) {
+				t.Errorf("new department is not among the children of %s", parentId)
+			}
+
+			created.Name = "Renamed"
+			created.ManagerId = people[5].Id
+			if err := editor.UpdateDepartment(*created); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := editor.GetDepartmentById(created.Id); got == nil || got.Name != "Renamed" || got.ManagerId != people[5].Id {
+				t.Errorf("department after update is %+v, want the new name and manager", got)
+			}
+
+			if err := editor.DeleteDepartment(created.Id); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := editor.GetDepartmentById(created.Id); err == nil {
+				t.Error("department is still found after delete")
+			}
+		})
+	}
+}
+
+func TestDepartmentWritesRejected(t *testing.T) {
+	for name, editor := range editors(t, 250) {
+		t.Run(name, func(t *testing.T) {
+			departments, _ := editor.GetDepartments()
+			root, child := departments[0], departments[1]
+			ref := func(id string) *string { return &id }
+
+			for _, tt := range []struct {
+				name string
+				dept services.Department
+				want string
+			}{
+				{"duplicate Id", services.Department{Id: child.Id, Name: "Copy"}, "already exists"},
+				{"missing name", services.Department{Name: " "}, "name is required"},
+				{"manager not found", services.Department{Name: "New", ManagerId: "missing"}, "manager with Id missing not found"},
+				{"parent not found", services.Department{Name: "New", ParentDepartmentId: ref("missing")}, "parent department with Id missing not found"},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					_, err := editor.CreateDepartment(tt.dept)
+					if err == nil || !strings.Contains(err.Error(), tt.want) {
+						t.Errorf("CreateDepartment error = %v, want one containing %q", err, tt.want)
+					}
+				})
+			}
+
+			for _, tt := range []struct {
+				name     string
+				parentId string
+			}{
+				{"parent is itself", root.Id},
+				{"parent is a descendant", child.Id},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					moved := root
+					moved.ParentDepartmentId = ref(tt.parentId)
+					err := editor.UpdateDepartment(moved)
+					if err == nil || !strings.Contains(err.Error(), "cycle") {
+						t.Errorf("UpdateDepartment error = %v, want a cycle error", err)
+					}
+				})
+			}
+
+			if err := editor.UpdateDepartment(services.Department{Id: "missing", Name: "Yok"}); err == nil {
+				t.Error("UpdateDepartment accepted an unknown Id")
+			}
+			if err := editor.DeleteDepartment("missing"); err == nil {
+				t.Error("DeleteDepartment accepted an unknown Id")
+			}
+			if err := editor.DeleteDepartment(child.Id); err == nil || !strings.Contains(err.Error(), "still has members") {
+				t.Errorf("DeleteDepartment of a department with members error = %v, want the members guard", err)
+			}
+
+			empty, err := editor.CreateDepartment(services.Department{Name: "Empty"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := editor.CreateDepartment(services.Department{Name: "Leaf", ParentDepartmentId: &empty.Id}); err != nil {
+				t.Fatal(err)
+			}
+			if err := editor.DeleteDepartment(empty.Id); err == nil || !strings.Contains(err.Error(), "still has sub-departments") {
+				t.Errorf("DeleteDepartment of a department with children error = %v, want the sub-departments guard", err)
+			}
+
+			if got, _ := editor.GetDepartmentById(root.Id); got == nil || got.HasParent() {
+				t.Errorf("root department is %+v after the rejected moves, want it top-level", got)
+			}
+		})
+	}
+}
+
+func TestJSONWritesReplaceFilesAtomically(t *testing.T) {
+	dir, err := servicestest.WriteJSON(t.TempDir(), 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir.PeoplePath, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dir.CreatePerson(services.Person{FirstName: "Yeni", LastName: "Kişi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(dir.PeoplePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("people.json has mode %v after the write, want 0600", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(dir.PeoplePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != filepath.Base(dir.PeoplePath) && entry.Name() != filepath.Base(dir.DepartmentsPath) {
+			t.Errorf("write left %s behind", entry.Name())
+		}
+	}
+	if people, err := dir.GetPeople(); err != nil || len(people) != 251 {
+		t.Errorf("GetPeople after the write = %d people, %v; want 251", len(people), err)
+	}
+}
+
+func BenchmarkApplyChanges(b *testing.B) {
+	dir, err := servicestest.WriteJSON(b.TempDir(), 10_000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	people, _ := dir.GetPeople()
+
+	for b.Loop() {
+		if err := dir.ApplyChanges(nil, people[:2_000]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}